@@ -0,0 +1,132 @@
+package konfig
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OriginAlias is the loader name reported in the changes when values are merged again after an alias is added
+const OriginAlias = "alias"
+
+// aliases maps old keys to their new key
+type aliases map[string]string
+
+// key returns the new key of the key k, if k or one of its path prefixes is an old key.
+// Otherwise it returns k.
+func (a aliases) key(k string) string {
+	if nk, ok := a[k]; ok {
+		return nk
+	}
+
+	var nk = k
+	var l = 0
+	for ok, nok := range a {
+		if len(ok) > l {
+			if kk, found := stripPrefix(k, ok); found && ok != "" {
+				nk = nok + KeySep + kk
+				l = len(ok)
+			}
+		}
+	}
+	return nk
+}
+
+// oldKeys returns the old keys of the key k
+func (a aliases) oldKeys(k string) []string {
+	var oks []string
+	for ok, nok := range a {
+		if nok == k {
+			oks = append(oks, ok)
+		} else if kk, found := stripPrefix(k, nok); found && nok != "" {
+			oks = append(oks, ok+KeySep+kk)
+		}
+	}
+	return oks
+}
+
+// expand returns the values of m along with their values under their old keys
+func (a aliases) expand(m s) s {
+	if len(a) == 0 {
+		return m
+	}
+	var nm = make(s, len(m))
+	for k, v := range m {
+		nm[k] = v
+		for _, ok := range a.oldKeys(k) {
+			if _, exists := m[ok]; !exists {
+				nm[ok] = v
+			}
+		}
+	}
+	return nm
+}
+
+// Alias makes the old keys aliases of the key k in the global store
+func Alias(k string, oldKeys ...string) Store {
+	return instance().Alias(k, oldKeys...)
+}
+
+// Alias makes the old keys aliases of the key k, reads, bindings, strict keys and hooks treat them as the key k.
+// Values set by loaders under an old key are stored under k, with a deprecation warning logged once per loader and old key.
+// If a loader sets both keys, k wins. Across loaders the precedence of loaders applies,
+// an old key set by a loader with a higher precedence wins over k set by another loader.
+// Aliases should be added before registering hooks on the old keys.
+func (c *S) Alias(k string, oldKeys ...string) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var a = c.aliases.Load().(aliases)
+	var na = make(aliases, len(a)+len(oldKeys))
+	for ok, nk := range a {
+		na[ok] = nk
+	}
+	for _, ok := range oldKeys {
+		na[c.normalize(ok)] = c.normalize(k)
+	}
+	c.aliases.Store(na)
+
+	// the values already in the store are merged again under their new key
+	if len(c.layers) != 0 || len(c.sets) != 0 {
+		if _, err := c.applyLocked(nil, nil, OriginAlias, false); err != nil {
+			c.cfg.Logger.Get().Error("Error while merging values of aliases: " + err.Error())
+		}
+	}
+
+	return c
+}
+
+// canonical returns the normalized key k, or its new key if k is an alias
+func (c *S) canonical(k string) string {
+	return c.aliases.Load().(aliases).key(c.normalize(k))
+}
+
+// canonicalKeys returns the canonical keys of the given keys
+func (c *S) canonicalKeys(keys []string) []string {
+	var nks = make([]string, len(keys))
+	for i, k := range keys {
+		nks[i] = c.canonical(k)
+	}
+	return nks
+}
+
+// deprecated reports that the loader wl set the old key ok of the key k,
+// it is reported once per loader and key so that reloads don't repeat it
+func (c *S) deprecated(wl *loaderWatcher, ok, k string) {
+	if _, reported := wl.deprecated[ok]; reported {
+		return
+	}
+	if wl.deprecated == nil {
+		wl.deprecated = make(map[string]struct{})
+	}
+	wl.deprecated[ok] = struct{}{}
+
+	c.cfg.Logger.Get().Warn(
+		fmt.Sprintf("config key \"%s\" set by loader %s is deprecated, use \"%s\"", ok, wl.Name(), k),
+	)
+	if c.cfg.Metrics {
+		c.metrics[MetricsDeprecatedKey].(*prometheus.CounterVec).
+			WithLabelValues(c.name, wl.Name(), ok).
+			Inc()
+	}
+}
@@ -0,0 +1,97 @@
+package konfig
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lalamove/nui/nlogger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlias(t *testing.T) {
+	t.Run(
+		"old key set by a loader",
+		func(t *testing.T) {
+			var buf bytes.Buffer
+			var cfg = DefaultConfig()
+			cfg.Logger = nlogger.NewProvider(nlogger.New(&buf, ""))
+			cfg.Metrics = true
+			var c = New(cfg)
+			c.Alias("redis.addr", "redis_addr")
+			c.Strict("redis_addr")
+
+			var ranKeyHook int
+			c.RegisterKeyHook("redis_addr", func(Store) error {
+				ranKeyHook++
+				return nil
+			})
+
+			var l = &valuesLoader{values: Values{"redis_addr": "localhost:6379"}}
+			var cl = c.RegisterLoader(l)
+			require.Nil(t, c.Load())
+
+			require.Equal(t, "localhost:6379", c.String("redis.addr"))
+			require.Equal(t, "localhost:6379", c.String("redis_addr"))
+			require.True(t, c.Exists("redis_addr"))
+			require.Equal(t, "localhost:6379", c.View().String("redis_addr"))
+			require.Equal(t, []string{"redis.addr"}, c.Keys(""))
+			require.Contains(t, buf.String(), `config key "redis_addr" set by loader dummy is deprecated, use "redis.addr"`)
+			require.Equal(
+				t,
+				float64(1),
+				testutil.ToFloat64(
+					c.metrics[MetricsDeprecatedKey].(*prometheus.CounterVec).WithLabelValues(c.name, "dummy", "redis_addr"),
+				),
+			)
+
+			// the deprecation is reported once per loader and key
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, 1, strings.Count(buf.String(), `config key "redis_addr" set by loader dummy is deprecated`))
+			require.Equal(
+				t,
+				float64(1),
+				testutil.ToFloat64(
+					c.metrics[MetricsDeprecatedKey].(*prometheus.CounterVec).WithLabelValues(c.name, "dummy", "redis_addr"),
+				),
+			)
+
+			// the new key wins
+			l.values = Values{"redis_addr": "old:6379", "redis.addr": "new:6379"}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, "new:6379", c.String("redis_addr"))
+			require.Equal(t, 2, ranKeyHook)
+
+			c.Set("redis_addr", "set:6379")
+			require.Equal(t, "set:6379", c.String("redis.addr"))
+		},
+	)
+
+	t.Run(
+		"prefix alias and bindings",
+		func(t *testing.T) {
+			type Cache struct {
+				Addr string `konfig:"addr"`
+			}
+			type Config struct {
+				Old string `konfig:"cache.addr"`
+			}
+
+			var c = New(DefaultConfig())
+			c.Set("cache.addr", "localhost")
+			c.Bind(Config{})
+			c.Alias("redis", "cache")
+			var b = c.BindPrefix("cache", Cache{})
+
+			require.Equal(t, "localhost", c.String("redis.addr"))
+			require.False(t, c.View().Exists("redis.cache.addr"))
+
+			c.Set("redis.addr", "remote")
+			require.Equal(t, Cache{Addr: "remote"}, b.Value())
+			require.Equal(t, Config{Old: "remote"}, c.Value())
+		},
+	)
+}
@@ -0,0 +1,223 @@
+package konfig
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrIncorrectBindingValue is the error thrown when trying to bind an invalid type to a prefix of a config store
+var ErrIncorrectBindingValue = errors.New("BindPrefix takes a map[string]interface{} or a struct")
+
+// Binding is a value bound to the keys under a prefix of a store
+type Binding struct {
+	s      *S
+	prefix string
+	v      *value
+
+	// view is the view the binding was created on, such a binding is not registered in the store:
+	// its value is rebuilt from the current values of the store when it is read
+	view    *subStore
+	mut     *sync.Mutex
+	version uint64
+	built   bool
+	unbound bool
+}
+
+// BindPrefix binds a value to the keys under the prefix p of the global store
+func BindPrefix(p string, v interface{}) *Binding {
+	return instance().BindPrefix(p, v)
+}
+
+// BindPrefix binds a value (either a map[string]interface{} or a struct) to the keys under the prefix p,
+// the prefix is stripped from the keys. Multiple values can be bound to different prefixes,
+// all bindings are updated together when the store loads.
+func (c *S) BindPrefix(p string, v interface{}) *Binding {
+	var b = &Binding{
+		s:      c,
+		prefix: c.canonical(strings.TrimSuffix(p, KeySep)),
+		v:      c.newValue(v, ErrIncorrectBindingValue),
+	}
+
+	// set the values already in the store
+	if err := b.v.setValues(c.m.Load().(s).sub(b.prefix)); err != nil {
+		c.cfg.Logger.Get().Error(err.Error())
+	}
+
+	c.mut.Lock()
+	c.bindings = append(c.bindings, b)
+	c.mut.Unlock()
+
+	// seed the default values and read validation rules from the struct tags
+	if !b.v.isMap {
+		b.v.schema = c.bindTags(b.v.vt, b.keyPrefix())
+	}
+
+	return b
+}
+
+// Value returns the bound value
+func (b *Binding) Value() interface{} {
+	if b.view != nil {
+		b.refresh()
+	}
+	return b.v.v.Load()
+}
+
+// refresh rebuilds the value of a binding created on a view if the values of the store changed since it was built
+func (b *Binding) refresh() {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	var sn = b.s.Snapshot()
+	if b.unbound || (b.built && sn.Version == b.version) {
+		return
+	}
+
+	// bound values can use the old keys of aliases
+	var nv, err = b.v.build(sn.aliases.expand(sn.m).sub(b.prefix))
+	if err != nil {
+		b.s.cfg.Logger.Get().Error("Error while setting bound value: " + err.Error())
+	}
+	b.v.v.Store(nv)
+	b.version, b.built = sn.Version, true
+}
+
+// AddHooks adds hooks run when keys under the prefix of the binding change
+func (b *Binding) AddHooks(hooks ...func(Store) error) *Binding {
+	b.s.mut.Lock()
+	defer b.s.mut.Unlock()
+
+	// hooks of a binding created on a view are given the view
+	var st Store = b.s
+	if b.view != nil {
+		st = b.view
+	}
+
+	for _, h := range hooks {
+		var h = h
+		b.s.changeHooks = append(b.s.changeHooks, changeHook{
+			prefix:  b.prefix,
+			binding: b,
+			f: func(ChangeSet) error {
+				return h(st)
+			},
+		})
+	}
+	return b
+}
+
+// Unbind removes the binding and its hooks from the store, the value is not updated anymore
+func (b *Binding) Unbind() {
+	if b.view != nil {
+		b.mut.Lock()
+		b.unbound = true
+		b.mut.Unlock()
+	}
+
+	b.s.mut.Lock()
+	defer b.s.mut.Unlock()
+
+	var bindings = make([]*Binding, 0, len(b.s.bindings))
+	for _, bb := range b.s.bindings {
+		if bb != b {
+			bindings = append(bindings, bb)
+		}
+	}
+	b.s.bindings = bindings
+
+	var hooks = make(changeHooks, 0, len(b.s.changeHooks))
+	for _, h := range b.s.changeHooks {
+		if h.binding != b {
+			hooks = append(hooks, h)
+		}
+	}
+	b.s.changeHooks = hooks
+}
+
+// keyPrefix returns the prefix of the keys of the binding, it is empty if the binding has no prefix
+func (b *Binding) keyPrefix() string {
+	if b.prefix == "" {
+		return ""
+	}
+	return b.prefix + KeySep
+}
+
+// stripPrefix returns the key k stripped from the path prefix p and whether k is under p
+func stripPrefix(k, p string) (string, bool) {
+	if p == "" {
+		return k, true
+	}
+	if !strings.HasPrefix(k, p+KeySep) {
+		return "", false
+	}
+	return k[len(p)+len(KeySep):], true
+}
+
+// sub returns the values of the keys under the path prefix p with the prefix stripped
+func (m s) sub(p string) s {
+	var x = make(s)
+	for k, v := range m {
+		if kk, ok := stripPrefix(k, p); ok {
+			x[kk] = v
+		}
+	}
+	return x
+}
+
+// validateBound validates the values of the snapshot against the rules of the bound values
+func (c *S) validateBound(sn *Snapshot) error {
+	if c.v != nil && c.v.schema != nil {
+		if err := c.v.schema.Validate(sn); err != nil {
+			return err
+		}
+	}
+	for _, b := range c.bindings {
+		if b.v.schema != nil {
+			if err := b.v.schema.Validate(sn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setBound sets the values nm on the bound value and all bindings.
+// Values which cannot be decoded are logged and left out of the bound values,
+// with Config.StrictBinding bound values are replaced only if the values can be decoded into all of them.
+func (c *S) setBound(nm s) error {
+	// bound values can use the old keys of aliases
+	nm = c.aliases.Load().(aliases).expand(nm)
+
+	var vals = make([]*value, 0, len(c.bindings)+1)
+	var nvs = make([]interface{}, 0, len(c.bindings)+1)
+	var build = func(val *value, x s) error {
+		var nv, err = val.build(x)
+		if err != nil {
+			if c.cfg.StrictBinding {
+				return err
+			}
+			c.cfg.Logger.Get().Error("Error while setting bound value: " + err.Error())
+		}
+		vals = append(vals, val)
+		nvs = append(nvs, nv)
+		return nil
+	}
+
+	if c.v != nil {
+		if err := build(c.v, nm); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range c.bindings {
+		if err := build(b.v, nm.sub(b.prefix)); err != nil {
+			return err
+		}
+	}
+
+	for i, val := range vals {
+		val.v.Store(nvs[i])
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package konfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindPrefix(t *testing.T) {
+	type HTTPConfig struct {
+		Addr    string `konfig:"addr" default:":8080"`
+		Timeout int    `konfig:"timeout"`
+	}
+	type DBConfig struct {
+		Host string `konfig:"host"`
+		Port int    `konfig:"port" validate:"min=1"`
+	}
+
+	t.Run(
+		"multiple bindings",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.Set("db.host", "localhost")
+
+			var httpB = c.BindPrefix("http", HTTPConfig{})
+			var dbB = c.BindPrefix("db.", DBConfig{})
+			var mB = c.BindPrefix("db", map[string]interface{}{})
+
+			require.Equal(t, HTTPConfig{Addr: ":8080"}, httpB.Value())
+			require.Equal(t, DBConfig{Host: "localhost"}, dbB.Value())
+
+			var ranHTTP, ranDB int
+			httpB.AddHooks(func(Store) error {
+				ranHTTP++
+				return nil
+			})
+			dbB.AddHooks(func(Store) error {
+				ranDB++
+				return nil
+			})
+
+			var l = &valuesLoader{values: Values{"http.timeout": 10, "db.port": 5432, "dbx.port": 1}}
+			var cl = c.RegisterLoader(l)
+			require.Nil(t, c.Load())
+
+			require.Equal(t, HTTPConfig{Addr: ":8080", Timeout: 10}, httpB.Value())
+			require.Equal(t, DBConfig{Host: "localhost", Port: 5432}, dbB.Value())
+			require.Equal(t, map[string]interface{}{"host": "localhost", "port": 5432}, mB.Value())
+			require.Equal(t, 1, ranHTTP)
+			require.Equal(t, 1, ranDB)
+
+			// a value which cannot be decoded in one binding fails the load for all bindings
+			l.values = Values{"http.timeout": 20, "db.port": "abc"}
+			require.NotNil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, 10, httpB.Value().(HTTPConfig).Timeout)
+
+			// validation rules of the bindings are checked
+			l.values = Values{"http.timeout": 20, "db.port": 0}
+			require.NotNil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, 10, httpB.Value().(HTTPConfig).Timeout)
+
+			// only the db hooks run
+			l.values = Values{"http.timeout": 10, "db.port": 5433}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, 5433, dbB.Value().(DBConfig).Port)
+			require.Equal(t, 1, ranHTTP)
+			require.Equal(t, 2, ranDB)
+
+			// unbound values are not updated anymore
+			dbB.Unbind()
+			l.values = Values{"http.timeout": 10, "db.port": 5434}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, 5433, dbB.Value().(DBConfig).Port)
+			require.Equal(t, 2, ranDB)
+
+			c.Set("http.timeout", 30)
+			require.Equal(t, 30, httpB.Value().(HTTPConfig).Timeout)
+		},
+	)
+
+	t.Run(
+		"invalid type",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			require.Panics(t, func() { c.BindPrefix("foo", 1) })
+		},
+	)
+}
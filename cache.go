@@ -0,0 +1,315 @@
+package konfig
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrCacheCorrupted is the error returned when a cache file can't be decrypted
+var ErrCacheCorrupted = errors.New("Cache file is corrupted")
+
+var (
+	_ FallbackLoader = (*CacheLoader)(nil)
+	_ LoaderWatcher  = (*CacheLoader)(nil)
+	_ LoaderContext  = (*CacheLoader)(nil)
+)
+
+// FallbackLoader is a Loader which can load fallback values when it fails after all its retries
+type FallbackLoader interface {
+	Loader
+	// Save saves the values loaded successfully by the loader
+	Save(Values) error
+	// LoadFallback loads the values last saved
+	LoadFallback(Values) error
+}
+
+// CacheLoader is a FallbackLoader keeping the last known good values of a loader in a file
+type CacheLoader struct {
+	Loader
+	Watcher
+	path string
+	key  []byte
+	mut  sync.Mutex
+	sum  [sha256.Size]byte
+}
+
+// CachedLoader returns a CacheLoader saving the values of the loader l in the file at path.
+// When l fails after all its retries before it has been loaded, the store serves the values of the file and reports them as stale.
+// Once it has been loaded, the store keeps its current values and reports them as stale.
+// Values are saved as JSON, so they are read back with JSON types.
+// If l is a LoaderWatcher, the CacheLoader uses its watcher.
+func CachedLoader(l Loader, path string) *CacheLoader {
+	var w, ok = l.(Watcher)
+	if !ok {
+		w = NopWatcher{}
+	}
+	return &CacheLoader{
+		Loader:  l,
+		Watcher: w,
+		path:    path,
+	}
+}
+
+// WithEncryptionKey encrypts the cache file with AES-GCM, key must be 16, 24 or 32 bytes long
+func (cl *CacheLoader) WithEncryptionKey(key []byte) *CacheLoader {
+	cl.mut.Lock()
+	defer cl.mut.Unlock()
+
+	cl.key = key
+	return cl
+}
+
+// Load implements Loader, it loads the values of the underlying loader
+func (cl *CacheLoader) Load(v Values) error {
+	return cl.LoadContext(context.Background(), v)
+}
+
+// LoadContext implements LoaderContext, it loads the values of the underlying loader
+func (cl *CacheLoader) LoadContext(ctx context.Context, v Values) error {
+	return NewLoaderContext(cl.Loader).LoadContext(ctx, v)
+}
+
+// Source returns the source of the underlying loader if it implements Sourcer
+func (cl *CacheLoader) Source() string {
+	if s, ok := cl.Loader.(Sourcer); ok {
+		return s.Source()
+	}
+	return ""
+}
+
+// RetryPolicy returns the retry policy of the underlying loader
+func (cl *CacheLoader) RetryPolicy() RetryPolicy {
+	if p, ok := cl.Loader.(RetryPolicyProvider); ok {
+		return p.RetryPolicy()
+	}
+	return loaderRetryPolicy{cl.Loader}
+}
+
+// Save writes the values v to the cache file. The file is replaced atomically and is not written
+// if the values did not change since the last save.
+func (cl *CacheLoader) Save(v Values) error {
+	var b, err = json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	cl.mut.Lock()
+	defer cl.mut.Unlock()
+
+	var sum = sha256.Sum256(b)
+	if sum == cl.sum {
+		return nil
+	}
+
+	if cl.key != nil {
+		if b, err = seal(cl.key, b); err != nil {
+			return err
+		}
+	}
+
+	if err := writeFileAtomic(cl.path, b); err != nil {
+		return err
+	}
+	cl.sum = sum
+
+	return nil
+}
+
+// LoadFallback loads the values of the cache file in v
+func (cl *CacheLoader) LoadFallback(v Values) error {
+	cl.mut.Lock()
+	defer cl.mut.Unlock()
+
+	var b, err = os.ReadFile(cl.path)
+	if err != nil {
+		return err
+	}
+
+	if cl.key != nil {
+		if b, err = open(cl.key, b); err != nil {
+			return err
+		}
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	for k, vv := range m {
+		v.Set(k, vv)
+	}
+
+	return nil
+}
+
+// save saves the values v of the loader wl if it is a FallbackLoader
+func (c *S) save(wl *loaderWatcher, v Values) {
+	var fl, ok = wl.Loader.(FallbackLoader)
+	if !ok {
+		return
+	}
+	if err := fl.Save(v); err != nil {
+		c.cfg.Logger.Get().Error(
+			fmt.Sprintf("Error while saving values of loader %s: %s", wl.Name(), err.Error()),
+		)
+	}
+}
+
+// fetched holds the values fetched from a loader,
+// stale is the error of the loader when the values are its fallback values
+type fetched struct {
+	values Values
+	stale  error
+}
+
+// committed records that the fetched values f of the loader wl have been committed,
+// fresh values are saved as the fallback values of the loader
+func (c *S) committed(wl *loaderWatcher, f fetched) {
+	if f.stale != nil {
+		c.cfg.Logger.Get().Warn(
+			fmt.Sprintf("Loader %s failed, serving stale config: %s", wl.Name(), f.stale.Error()),
+		)
+		c.setStale(wl, f.stale)
+		return
+	}
+	c.save(wl, f.values)
+	c.setStale(wl, nil)
+}
+
+// fallback loads the fallback values of the loader wl if it is a FallbackLoader, err is the error of the loader.
+// Fallback values are only served until the loader has been loaded, after that the store keeps the values
+// of the loader which are marked as stale and err is returned. If there are no fallback values, it returns err.
+func (c *S) fallback(wl *loaderWatcher, err error) (fetched, error) {
+	var fl, ok = wl.Loader.(FallbackLoader)
+	if !ok {
+		return fetched{}, err
+	}
+
+	c.mut.Lock()
+	var loaded = !wl.loadedAt.IsZero()
+	c.mut.Unlock()
+	if loaded {
+		c.cfg.Logger.Get().Warn(
+			fmt.Sprintf("Loader %s failed, keeping its stale config: %s", wl.Name(), err.Error()),
+		)
+		c.setStale(wl, err)
+		return fetched{}, err
+	}
+
+	var v = make(Values)
+	if fErr := fl.LoadFallback(v); fErr != nil {
+		c.cfg.Logger.Get().Error(
+			fmt.Sprintf("Error while loading fallback values of loader %s: %s", wl.Name(), fErr.Error()),
+		)
+		return fetched{}, err
+	}
+
+	return fetched{values: v, stale: err}, nil
+}
+
+// setStale records that the loader wl serves stale values because of the error err, or that it serves fresh values if err is nil
+func (c *S) setStale(wl *loaderWatcher, err error) {
+	c.mut.Lock()
+	var wasStale = wl.stale != nil
+	wl.stale = err
+	if err != nil && !wasStale {
+		wl.staleAt = time.Now()
+	}
+	c.mut.Unlock()
+
+	if wasStale == (err != nil) {
+		return
+	}
+
+	if err == nil {
+		c.cfg.Logger.Get().Info(fmt.Sprintf("Loader %s recovered, serving fresh config", wl.Name()))
+	}
+
+	if c.cfg.Metrics {
+		var g = c.metrics[MetricsStaleLoader].(*prometheus.GaugeVec).WithLabelValues(c.name, wl.Name())
+		if err != nil {
+			g.Set(1)
+		} else {
+			g.Set(0)
+		}
+	}
+}
+
+// writeFileAtomic writes b to a temporary file then renames it to path,
+// so that readers never see a partially written file
+func writeFileAtomic(path string, b []byte) error {
+	var f, err = os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// seal encrypts b with AES-GCM, the nonce is prepended to the cipher text
+func seal(key, b []byte) ([]byte, error) {
+	var gcm, err = newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, b, nil), nil
+}
+
+// open decrypts b encrypted with seal
+func open(key, b []byte) ([]byte, error) {
+	var gcm, err = newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) < gcm.NonceSize() {
+		return nil, ErrCacheCorrupted
+	}
+
+	b, err = gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrCacheCorrupted
+	}
+
+	return b, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	var block, err = aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,161 @@
+package konfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheLoader(t *testing.T) {
+	t.Run(
+		"save and load fallback",
+		func(t *testing.T) {
+			var path = filepath.Join(t.TempDir(), "cache.json")
+			var cl = CachedLoader(&valuesLoader{}, path)
+
+			require.Nil(t, cl.Save(Values{"foo": "bar", "port": 8080}))
+
+			var v = Values{}
+			require.Nil(t, cl.LoadFallback(v))
+			require.Equal(t, Values{"foo": "bar", "port": float64(8080)}, v)
+
+			// values which did not change are not written again
+			require.Nil(t, os.Remove(path))
+			require.Nil(t, cl.Save(Values{"foo": "bar", "port": 8080}))
+			_, err := os.Stat(path)
+			require.True(t, os.IsNotExist(err))
+		},
+	)
+
+	t.Run(
+		"encrypted",
+		func(t *testing.T) {
+			var path = filepath.Join(t.TempDir(), "cache")
+			var key = []byte("0123456789abcdef0123456789abcdef")
+			var cl = CachedLoader(&valuesLoader{}, path).WithEncryptionKey(key)
+
+			require.Nil(t, cl.Save(Values{"password": "secret"}))
+
+			var b, err = os.ReadFile(path)
+			require.Nil(t, err)
+			require.NotContains(t, string(b), "secret")
+
+			var v = Values{}
+			require.Nil(t, cl.LoadFallback(v))
+			require.Equal(t, "secret", v["password"])
+
+			cl.WithEncryptionKey([]byte("fedcba9876543210fedcba9876543210"))
+			require.Equal(t, ErrCacheCorrupted, cl.LoadFallback(Values{}))
+		},
+	)
+
+	t.Run(
+		"store serves stale values when the loader fails",
+		func(t *testing.T) {
+			var path = filepath.Join(t.TempDir(), "cache.json")
+			var l = &flakyLoader{
+				valuesLoader: valuesLoader{values: Values{"foo": "bar"}},
+			}
+			var c = New(DefaultConfig())
+			var cl = c.RegisterLoader(CachedLoader(l, path))
+
+			require.Nil(t, c.Load())
+			require.False(t, c.Health().Stale)
+
+			// the loader fails, the cached values are served
+			var err = errors.New("unavailable")
+			c = New(DefaultConfig())
+			l = &flakyLoader{
+				valuesLoader: valuesLoader{values: Values{"foo": "baz"}},
+				errs:         []error{err},
+			}
+			cl = c.RegisterLoader(CachedLoader(l, path))
+
+			require.Nil(t, c.Load())
+			require.Equal(t, "bar", c.MustString("foo"))
+
+			var h = c.Health()
+			require.True(t, h.Stale)
+			require.Len(t, h.Loaders, 1)
+			require.True(t, h.Loaders[0].Stale)
+			require.Equal(t, "unavailable", h.Loaders[0].StaleError)
+			require.NotNil(t, h.Loaders[0].StaleSince)
+
+			// the loader recovers
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, "baz", c.MustString("foo"))
+			require.False(t, c.Health().Stale)
+		},
+	)
+
+	t.Run(
+		"reloads keep the current values when the loader fails",
+		func(t *testing.T) {
+			var path = filepath.Join(t.TempDir(), "cache.json")
+			var l = &flakyLoader{
+				valuesLoader: valuesLoader{values: Values{"port": 8080}},
+			}
+			var c = New(DefaultConfig())
+			var cl = c.RegisterLoader(CachedLoader(l, path))
+
+			var changes int
+			c.OnChange("", func(ChangeSet) error {
+				changes++
+				return nil
+			})
+
+			require.Nil(t, c.Load())
+			var version = c.Version()
+
+			// the cached values don't replace the values of the loader
+			var err = errors.New("unavailable")
+			l.errs, l.runs = []error{err}, 0
+			require.Equal(t, err, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, 8080, c.Get("port"))
+			require.Equal(t, version, c.Version())
+			require.Equal(t, 1, changes)
+			var h = c.Health()
+			require.True(t, h.Stale)
+			require.False(t, h.Ready)
+
+			// the loader recovers
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			h = c.Health()
+			require.False(t, h.Stale)
+			require.True(t, h.Ready)
+		},
+	)
+
+	t.Run(
+		"values are saved once committed",
+		func(t *testing.T) {
+			var path = filepath.Join(t.TempDir(), "cache.json")
+			var c = New(DefaultConfig())
+			c.RegisterLoader(CachedLoader(&valuesLoader{values: Values{"foo": "bar"}}, path))
+			c.Strict("missing")
+
+			require.NotNil(t, c.Load())
+			var _, err = os.Stat(path)
+			require.True(t, os.IsNotExist(err))
+		},
+	)
+
+	t.Run(
+		"no cache file",
+		func(t *testing.T) {
+			var err = errors.New("unavailable")
+			var c = New(DefaultConfig())
+			c.RegisterLoader(CachedLoader(
+				&flakyLoader{errs: []error{err}},
+				filepath.Join(t.TempDir(), "cache.json"),
+			))
+
+			require.Equal(t, err, c.Load())
+			require.False(t, c.Health().Stale)
+		},
+	)
+}
@@ -0,0 +1,126 @@
+package konfig
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Change is the change of the value of a key. Old is nil when the key is added, New is nil when the key is removed.
+type Change struct {
+	Key string
+	Old interface{}
+	New interface{}
+}
+
+// ChangeSet is the list of changes applied to the store by a load
+type ChangeSet struct {
+	// Loader is the name of the loader whose load caused the changes
+	Loader string
+	// Added is the list of keys which were not set before the load
+	Added []Change
+	// Updated is the list of keys whose value changed
+	Updated []Change
+	// Removed is the list of keys which are not set anymore after the load
+	Removed []Change
+}
+
+// Len returns the number of changes in the ChangeSet
+func (cs ChangeSet) Len() int {
+	return len(cs.Added) + len(cs.Updated) + len(cs.Removed)
+}
+
+// Keys returns the keys changed in the ChangeSet
+func (cs ChangeSet) Keys() []string {
+	var keys = make([]string, 0, cs.Len())
+	for _, l := range [][]Change{cs.Added, cs.Updated, cs.Removed} {
+		for _, ch := range l {
+			keys = append(keys, ch.Key)
+		}
+	}
+	return keys
+}
+
+// Prefix returns the changes of the keys which are p or under the path p
+func (cs ChangeSet) Prefix(p string) ChangeSet {
+	var filter = func(l []Change) []Change {
+		var res []Change
+		for _, ch := range l {
+			if hasKeyPrefix(ch.Key, p) {
+				res = append(res, ch)
+			}
+		}
+		return res
+	}
+	return ChangeSet{
+		Loader:  cs.Loader,
+		Added:   filter(cs.Added),
+		Updated: filter(cs.Updated),
+		Removed: filter(cs.Removed),
+	}
+}
+
+// diff returns the ChangeSet from the values m to the values nm
+func diff(m, nm s) ChangeSet {
+	var cs ChangeSet
+	for k, v := range nm {
+		ov, ok := m[k]
+		if !ok {
+			cs.Added = append(cs.Added, Change{Key: k, New: v})
+		} else if !reflect.DeepEqual(ov, v) {
+			cs.Updated = append(cs.Updated, Change{Key: k, Old: ov, New: v})
+		}
+	}
+	for k, ov := range m {
+		if _, ok := nm[k]; !ok {
+			cs.Removed = append(cs.Removed, Change{Key: k, Old: ov})
+		}
+	}
+
+	for _, l := range [][]Change{cs.Added, cs.Updated, cs.Removed} {
+		sort.Slice(l, func(i, j int) bool { return l[i].Key < l[j].Key })
+	}
+
+	return cs
+}
+
+type changeHook struct {
+	prefix  string
+	f       func(ChangeSet) error
+	binding *Binding
+}
+
+type changeHooks []changeHook
+
+// run runs the hooks matching the changes, the loader name is resolved only if a hook runs
+func (ch changeHooks) run(cs ChangeSet, wl *loaderWatcher) error {
+	for _, h := range ch {
+		var pcs = cs.Prefix(h.prefix)
+		if pcs.Len() == 0 {
+			continue
+		}
+		if wl != nil && cs.Loader == "" {
+			cs.Loader = wl.Name()
+		}
+		pcs.Loader = cs.Loader
+		if err := h.f(pcs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnChange adds a hook to the global store run with the changes of the key p and all subkeys of p
+func OnChange(p string, f func(ChangeSet) error) Store {
+	return instance().OnChange(p, f)
+}
+
+// OnChange adds a hook run with the changes of the key p and all subkeys of p after each load changing them.
+// If the hook returns an error, the load is considered a failure.
+func (c *S) OnChange(p string, f func(ChangeSet) error) Store {
+	p = c.canonical(p)
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.changeHooks = append(c.changeHooks, changeHook{prefix: p, f: f})
+	return c
+}
@@ -0,0 +1,102 @@
+package konfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	var cs = diff(
+		s{"a": 1, "b": 1, "c": 1},
+		s{"a": 1, "b": 2, "d": 1},
+	)
+
+	require.Equal(
+		t,
+		ChangeSet{
+			Added:   []Change{{Key: "d", New: 1}},
+			Updated: []Change{{Key: "b", Old: 1, New: 2}},
+			Removed: []Change{{Key: "c", Old: 1}},
+		},
+		cs,
+	)
+	require.Equal(t, 3, cs.Len())
+	require.Equal(t, []string{"d", "b", "c"}, cs.Keys())
+}
+
+func TestChangeSetPrefix(t *testing.T) {
+	var cs = ChangeSet{
+		Loader:  "test",
+		Added:   []Change{{Key: "db.host", New: "localhost"}, {Key: "dbx.port", New: 1}},
+		Updated: []Change{{Key: "db", Old: 1, New: 2}},
+	}
+
+	var pcs = cs.Prefix("db")
+	require.Equal(t, "test", pcs.Loader)
+	require.Equal(t, []string{"db.host", "db"}, pcs.Keys())
+
+	require.Equal(t, []string{"db.host"}, cs.Prefix("db.").Keys())
+	require.Equal(t, 0, cs.Prefix("d").Len())
+}
+
+func TestOnChange(t *testing.T) {
+	t.Run(
+		"hooks receive the changes under their prefix",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &valuesLoader{values: Values{"db.host": "localhost", "db.port": 5432, "dbx.port": 1}}
+			var cl = c.RegisterLoader(l)
+
+			var changes []ChangeSet
+			c.OnChange("db", func(cs ChangeSet) error {
+				changes = append(changes, cs)
+				return nil
+			})
+
+			var ranKeyHook int
+			c.RegisterKeyHook("db", func(Store) error {
+				ranKeyHook++
+				return nil
+			})
+
+			require.Nil(t, c.Load())
+			require.Len(t, changes, 1)
+			require.Equal(t, "dummy", changes[0].Loader)
+			require.Equal(
+				t,
+				[]Change{{Key: "db.host", New: "localhost"}, {Key: "db.port", New: 5432}},
+				changes[0].Added,
+			)
+			require.Equal(t, 1, ranKeyHook)
+
+			// only dbx changes, db hooks should not run
+			l.values = Values{"db.host": "localhost", "db.port": 5432, "dbx.port": 2}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Len(t, changes, 1)
+			require.Equal(t, 1, ranKeyHook)
+
+			l.values = Values{"db.host": "remote"}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Len(t, changes, 2)
+			require.Equal(t, []Change{{Key: "db.host", Old: "localhost", New: "remote"}}, changes[1].Updated)
+			require.Equal(t, []Change{{Key: "db.port", Old: 5432}}, changes[1].Removed)
+			require.Equal(t, 2, ranKeyHook)
+		},
+	)
+
+	t.Run(
+		"hook error fails the load",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.RegisterLoader(&valuesLoader{values: Values{"foo": "bar"}})
+			c.OnChange("", func(cs ChangeSet) error {
+				return errors.New("")
+			})
+
+			require.NotNil(t, c.Load())
+		},
+	)
+}
@@ -15,7 +15,7 @@ func (cs Closers) Close() error {
 	var multiErr error
 	for _, closer := range cs {
 		if err := closer.Close(); err != nil {
-			multierror.Append(multiErr, err)
+			multiErr = multierror.Append(multiErr, err)
 		}
 	}
 	return multiErr
@@ -1,20 +1,22 @@
 package konfig
 
 import (
+	"context"
 	"errors"
 	"io"
 	"os"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/lalamove/nui/ngetter"
 	"github.com/lalamove/nui/nlogger"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 var _ Store = (*S)(nil)
+var _ Reader = (*S)(nil)
 
 var (
 	// ErrInvalidConfigFileFormat is the error returned when a problem is encountered when parsing the
@@ -60,6 +62,42 @@ type Config struct {
 	Metrics bool
 	// MaxWatcherPanics is the maximum number of times to restart a watcher when it panics, default is 0.
 	MaxWatcherPanics int
+	// OnFatal is called with the error when a Loader failure or a panic stops the store.
+	// If it is set, the process is not exited and ExitCode and NoExitOnError are ignored.
+	OnFatal func(error)
+	// SubscribeBuffer is the size of the buffer of the channels returned by Subscribe, default is 16.
+	// Events are never sent blocking, when the buffer of a subscriber is full events are dropped.
+	SubscribeBuffer int
+	// SubscribeDropPolicy is the policy applied when the buffer of a subscriber is full, default is DropNewest.
+	SubscribeDropPolicy DropPolicy
+	// MaxSnapshots is the number of snapshots kept in the history of the store, default is 10.
+	// If it is negative, no history is kept.
+	MaxSnapshots int
+	// Transactional makes Load load all loaders in a single transaction: the values of the store
+	// are replaced only if all loaders, checks and pre-commit hooks succeed.
+	// Watcher reloads load only the loader which changed, its values are applied atomically.
+	Transactional bool
+	// Interpolation resolves the references ${key}, ${env:NAME} and ${file:/path} in string values after the values of loaders are merged.
+	// A default is given with ${key:-default}.
+	Interpolation bool
+	// InterpolationFileDirs are the directories from which ${file:/path} references can read files,
+	// as values of remote loaders could otherwise read any local file. References to files outside
+	// of these directories are not resolved, by default no file can be read.
+	InterpolationFileDirs []string
+	// InterpolationEnvVars are the names of the environment variables ${env:NAME} references can read,
+	// as values of remote loaders could otherwise read any variable of the process. References to other
+	// variables are not resolved, by default no variable can be read.
+	InterpolationEnvVars []string
+	// KeyNormalizer normalizes the keys of the values of loaders and the keys read from the store,
+	// keys which are equal once normalized are the same key. NormalizeKey can be used to ignore case and separators.
+	// Keys of maps in bound values are decoded from the normalized keys, with NormalizeKey they are lower cased.
+	KeyNormalizer func(k string) string
+	// StrictBinding makes a load fail when a value cannot be decoded into a bound value, the values of the store are then left untouched.
+	// By default the error is logged and the fields which cannot be decoded are left empty.
+	StrictBinding bool
+	// ParallelLoad makes Load fetch all loaders concurrently, their values are still applied in registration order.
+	// Load returns all failures in a multierror instead of stopping at the first one.
+	ParallelLoad bool
 }
 
 // Store is the interface
@@ -78,10 +116,45 @@ type Store interface {
 	// RegisterKeyHook adds a hook to be run when the key changes.
 	// If a key has the given key as path prefix, it runs the hook as well.
 	RegisterKeyHook(k string, h func(Store) error) Store
+	// OnChange adds a hook run with the changes of the key p and all subkeys of p after each load changing them.
+	OnChange(p string, f func(ChangeSet) error) Store
+	// Subscribe returns a channel receiving an Event after each load changing the key p or a subkey of p,
+	// and a function to cancel the subscription. Sending events never blocks loads.
+	Subscribe(p string) (<-chan Event, func())
+
+	// Snapshot returns the current snapshot of the store
+	Snapshot() *Snapshot
+	// Version returns the current version of the store, it increases every time the values of the store change
+	Version() uint64
+	// History returns the snapshots kept in the history of the store, from the oldest to the newest
+	History() []*Snapshot
+	// Rollback restores the store to the snapshot with the given version and runs the hooks as a reload does
+	Rollback(version uint64) error
+	// View returns a read only view of the current values of the store, values read from the view are consistent with each other
+	View() *Snapshot
+
+	// BindPrefix binds a value (either a map[string]interface{} or a struct) to the keys under the prefix p
+	BindPrefix(p string, v interface{}) *Binding
+	// Secret marks keys as secret, their values should never be displayed
+	Secret(keys ...string) Store
+	// IsSecret checks if the key k is secret
+	IsSecret(k string) bool
+	// RegisterValidator adds a validator run on the new values of the store before they are committed
+	RegisterValidator(v Validator) Store
+	// RegisterPreCommitHook adds a hook run with the new values of the store before they are committed, an error discards the new values
+	RegisterPreCommitHook(f func(*Snapshot) error) Store
+	// Reload reloads all loaders in a single transaction, the values of the store are replaced only if everything succeeds
+	Reload() error
+	// ReloadContext reloads all loaders in a single transaction with the given context
+	ReloadContext(ctx context.Context) error
 
 	// Strict specifies mandatory keys on the konfig. When Strict is called, konfig will check that the specified keys are present, else it will return a non nil error.
 	// Then, after every following `Load` of a loader, it will check if the strict keys are still present in the konfig and consider the load a failure if a key is not present anymore.
 	Strict(...string) Store
+	// Alias makes the old keys aliases of the key k, reads, bindings, strict keys and hooks treat them as the key k.
+	Alias(k string, oldKeys ...string) Store
+	// SetMergeStrategy sets the strategy used to merge the values of loaders for keys with the path prefix p.
+	SetMergeStrategy(p string, m MergeStrategy) Store
 	// RunHooks runs all hooks and child groups hooks
 	RunHooks() error
 
@@ -94,8 +167,29 @@ type Store interface {
 	// watchers. If loading or starting watchers fails, loadwatch stops and returns a non nil error.
 	LoadWatch() error
 
+	// LoadContext loads all loaders registered in the store. If ctx is done, retries are aborted and it returns a non nil error.
+	LoadContext(ctx context.Context) error
+	// WatchContext starts all watchers registered in the store. Watchers are stopped when ctx is done.
+	WatchContext(ctx context.Context) error
+	// LoadWatchContext loads all loaders registered in the store, then starts watching all watchers until ctx is done.
+	LoadWatchContext(ctx context.Context) error
+	// LoadLoader loads the loader with the given name as a watcher reload does. If no loader has the name, it returns ErrLoaderNotFound.
+	LoadLoader(name string) error
+	// LoadLoaderContext loads the loader with the given name with the given context.
+	LoadLoaderContext(ctx context.Context, name string) error
+
+	// Close stops all watchers, closes the registered closers and waits for in-flight reloads and hooks to return.
+	// If ctx is done before the store is closed, Close returns ctx.Err().
+	Close(ctx context.Context) error
+	// Done returns a channel which is closed when the store is closed, either by a call to Close or by a loader failure.
+	Done() <-chan struct{}
+	// Err returns the error which caused the store to close, or the error returned by the closers.
+	Err() error
+
 	// Group lazyloads a child Store from the current store. If the group already exists, it just returns it, else it creates it and returns it. Groups are useful to namespace configs by domain.
 	Group(g string) Store
+	// Groups returns the sorted names of the child groups of the store.
+	Groups() []string
 
 	// Getter returns a GetterTyped for the given key k
 	Getter(k string) ngetter.GetterTyped
@@ -104,10 +198,23 @@ type Store interface {
 	Get(k string) interface{}
 	// MustGet tries to get the value with the key k from the store. If the key k does not exist in the store, MustGet panics.
 	MustGet(k string) interface{}
-	// Set sets the key k with the value v in the store.
-	Set(k string, v interface{})
+	// Set sets the key k with the value v in the store. The new values are checked as a load, an invalid value is not set and the error is returned.
+	Set(k string, v interface{}) error
+	// Delete removes the key k from the store along with the values set for it with Set or Override.
+	Delete(k string) error
+	// Override sets the key k to the value v until the ttl expires or the override is cleared, the override wins over the values of all loaders.
+	Override(k string, v interface{}, ttl time.Duration) error
+	// ClearOverride removes the override of the key k.
+	ClearOverride(k string) error
 	// Exists checks whether the key k is set in the store.
 	Exists(k string) bool
+	// Origin returns the origin of the key k: the loader which set it, its source and the load time.
+	// Loaders which also set the key but were overridden are listed in Shadowed.
+	Origin(k string) (Origin, bool)
+	// Explain returns the origins of all keys in the store sorted by key.
+	Explain() []Origin
+	// Health returns the health of the store and of its loaders.
+	Health() StoreHealth
 	// MustString tries to get the value with the key k from the store and casts it to a string. If the key k does not exist in the store, MustGet panics.
 	MustString(k string) string
 
@@ -160,6 +267,16 @@ type Store interface {
 	// StringMapString tries to get the value with the key k from the store and casts it to a map[string]string. If the key k does not exist it returns the Zero value.
 	StringMapString(k string) map[string]string
 
+	// Keys returns the sorted keys of the store with the path prefix p
+	Keys(p string) []string
+	// Tree returns the values of the keys under the path prefix p as nested maps, the prefix is stripped from the keys.
+	Tree(p string) map[string]interface{}
+	// Sub returns a live read only view of the keys under the path prefix p, the prefix is stripped from the keys.
+	Sub(p string) Store
+	// Slice rebuilds the elements of the array with the key p from its indexed keys (p.0.foo, p.1.foo...).
+	// The elements are returned in order of index and missing indexes are skipped. If no indexed key exists it returns nil.
+	Slice(p string) []map[string]interface{}
+
 	// Bind binds a value (either a map[string]interface{} or a struct) to the config store. When config values are set on the config store, they are also set on the bound value.
 	Bind(interface{})
 
@@ -173,16 +290,42 @@ type Store interface {
 
 // S is the concrete implementation of the Store
 type S struct {
-	name       string
-	cfg        *Config
-	m          *atomic.Value
-	mut        *sync.Mutex
-	groups     map[string]*S
-	v          *value
-	metrics    map[string]prometheus.Collector
-	strictKeys []string
-	loaded     bool
-	keyHooks   keyHooks
+	name           string
+	cfg            *Config
+	m              *atomic.Value
+	o              *atomic.Value
+	mut            *sync.Mutex
+	hooksMut       *sync.Mutex
+	groups         map[string]*S
+	v              *value
+	metrics        map[string]prometheus.Collector
+	strictKeys     []string
+	loaded         bool
+	keyHooks       keyHooks
+	changeHooks    changeHooks
+	quit           chan struct{}
+	done           chan struct{}
+	closeOnce      *sync.Once
+	wg             *sync.WaitGroup
+	errMut         *sync.Mutex
+	err            error
+	layers         []*loaderWatcher
+	raw            s
+	sets           Values
+	setAt          time.Time
+	overrides      map[string]*override
+	invalid        error
+	invalidAt      time.Time
+	strategies     map[string]MergeStrategy
+	subs           map[*subscription]struct{}
+	sn             *atomic.Value
+	aliases        *atomic.Value
+	history        []*Snapshot
+	preCommitHooks PreCommitHooks
+	validators     []Validator
+	secrets        map[string]struct{}
+	bindings       []*Binding
+	collisions     map[keyCollision]struct{}
 
 	WatcherLoaders []*loaderWatcher
 	WatcherClosers Closers
@@ -249,7 +392,7 @@ func RegisterLoaderWatcher(lw LoaderWatcher, loaderHooks ...func(Store) error) *
 func (c *S) RegisterLoaderWatcher(lw LoaderWatcher, loaderHooks ...func(Store) error) *ConfigLoader {
 	var lwatcher = c.newLoaderWatcher(lw, lw, loaderHooks)
 
-	c.WatcherClosers = append(c.WatcherClosers, lw)
+	c.WatcherClosers = append(c.WatcherClosers, lwatcher)
 	c.WatcherLoaders = append(
 		c.WatcherLoaders,
 		lwatcher,
@@ -285,7 +428,7 @@ func (kh keyHooks) add(k string, f func(Store) error) {
 func (kh keyHooks) runForKeys(keys []string, c *S) error {
 	for k, h := range kh {
 		for _, kk := range keys {
-			if strings.HasPrefix(kk, k) {
+			if hasKeyPrefix(kk, k) {
 				if err := h.Run(c); err != nil {
 					return err
 				}
@@ -305,6 +448,7 @@ func RegisterKeyHook(k string, f func(Store) error) Store {
 
 // RegisterKeyHook adds a hook to be run on the given key k and all subkeys of k
 func (c *S) RegisterKeyHook(k string, f func(Store) error) Store {
+	k = c.canonical(k)
 	if c.keyHooks == nil {
 		c.keyHooks = keyHooks{}
 	}
@@ -326,7 +470,7 @@ func (c *S) Strict(keys ...string) Store {
 }
 func (c *S) checkStrictKeys() error {
 	var m = c.m.Load().(s)
-	return m.checkStrictKeys(c.strictKeys)
+	return m.checkStrictKeys(c.canonicalKeys(c.strictKeys))
 }
 
 // RunHooks runs all hooks and child groups hooks
@@ -376,14 +520,123 @@ func Instance() Store {
 	return c
 }
 
-// Stop stops the config store
-func (c *S) stop() {
-	if err := c.WatcherClosers.Close(); err != nil {
-		c.cfg.Logger.Get().Error(err.Error())
+// Close closes the global config store
+func Close(ctx context.Context) error {
+	return instance().Close(ctx)
+}
+
+// Close stops all watchers, closes the registered closers and waits for in-flight reloads and hooks to return.
+// Child groups are closed as well. If ctx is done before the store is closed, Close returns ctx.Err().
+func (c *S) Close(ctx context.Context) error {
+	c.shutdown(nil)
+
+	c.mut.Lock()
+	var groups = make([]*S, 0, len(c.groups))
+	for _, gr := range c.groups {
+		groups = append(groups, gr)
 	}
+	c.mut.Unlock()
 
-	if err := c.Closers.Close(); err != nil {
-		c.cfg.Logger.Get().Error(err.Error())
+	var multiErr error
+	for _, gr := range groups {
+		if err := gr.Close(ctx); err != nil {
+			multiErr = multierror.Append(multiErr, err)
+		}
+	}
+
+	select {
+	case <-c.done:
+		if err := c.Err(); err != nil {
+			multiErr = multierror.Append(multiErr, err)
+		}
+		return multiErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done returns a channel which is closed when the global config store is closed
+func Done() <-chan struct{} {
+	return instance().Done()
+}
+
+// Done returns a channel which is closed when the store is closed and all watchers have returned
+func (c *S) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error which caused the global config store to close
+func Err() error {
+	return instance().Err()
+}
+
+// Err returns the error which caused the store to close, or the error returned by the closers.
+func (c *S) Err() error {
+	c.errMut.Lock()
+	defer c.errMut.Unlock()
+	return c.err
+}
+
+// shutdown stops the watchers and closes all registered Watchers and Closers once.
+// The done channel is closed when all watchers have returned.
+func (c *S) shutdown(err error) {
+	c.closeOnce.Do(func() {
+		// quit is closed with the lock held so that no watcher is added to the wait group
+		// once we wait for the watchers to return
+		c.mut.Lock()
+		close(c.quit)
+		c.mut.Unlock()
+
+		var multiErr error
+		if err != nil {
+			multiErr = multierror.Append(multiErr, err)
+		}
+
+		if cErr := c.WatcherClosers.Close(); cErr != nil {
+			c.cfg.Logger.Get().Error(cErr.Error())
+			multiErr = multierror.Append(multiErr, cErr)
+		}
+
+		if cErr := c.Closers.Close(); cErr != nil {
+			c.cfg.Logger.Get().Error(cErr.Error())
+			multiErr = multierror.Append(multiErr, cErr)
+		}
+
+		c.errMut.Lock()
+		c.err = multiErr
+		c.errMut.Unlock()
+
+		c.closeSubs()
+		c.stopOverrides()
+
+		go func() {
+			c.wg.Wait()
+			close(c.done)
+		}()
+	})
+}
+
+// addWatcher adds a watcher to the wait group of the store, it returns false if the store is closed
+func (c *S) addWatcher() bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	select {
+	case <-c.quit:
+		return false
+	default:
+	}
+	c.wg.Add(1)
+	return true
+}
+
+// stop stops the config store after a failure
+func (c *S) stop(err error) {
+	c.shutdown(err)
+
+	if c.cfg.OnFatal != nil {
+		c.cfg.OnFatal(err)
+		return
 	}
 
 	// exit on error unless specified
@@ -424,12 +677,31 @@ func newStore(cfg *Config) *S {
 	var m = make(s)
 	mValue.Store(m)
 
+	var oValue atomic.Value
+	oValue.Store(make(origins))
+
+	var snValue atomic.Value
+	snValue.Store(&Snapshot{m: m})
+
+	var aValue atomic.Value
+	aValue.Store(make(aliases))
+
 	var s = &S{
 		name:           cfg.Name,
 		m:              &mValue,
+		raw:            m,
+		o:              &oValue,
+		sn:             &snValue,
+		aliases:        &aValue,
 		cfg:            cfg,
 		mut:            &sync.Mutex{},
+		hooksMut:       &sync.Mutex{},
 		groups:         make(map[string]*S),
+		quit:           make(chan struct{}),
+		done:           make(chan struct{}),
+		closeOnce:      &sync.Once{},
+		wg:             &sync.WaitGroup{},
+		errMut:         &sync.Mutex{},
 		WatcherLoaders: make([]*loaderWatcher, 0, 10),
 		WatcherClosers: make(Closers, 0, 10),
 		Closers:        make(Closers, 0, 10),
@@ -1,6 +1,7 @@
 package konfig
 
 import (
+	"context"
 	"errors"
 	"log"
 	"os"
@@ -671,7 +672,7 @@ func TestStop(t *testing.T) {
 			var testCloser = &TestCloser{}
 			c.RegisterCloser(testCloser)
 			c.cfg.NoExitOnError = true
-			c.stop()
+			c.stop(nil)
 			require.Equal(t, true, testCloser.closed)
 		},
 	)
@@ -685,13 +686,114 @@ func TestStop(t *testing.T) {
 			}
 			c.RegisterCloser(testCloser)
 			c.cfg.NoExitOnError = true
-			c.stop()
+			c.stop(nil)
 			require.Equal(t, true, testCloser.closed)
 		},
 	)
 	reset()
 }
 
+func TestClose(t *testing.T) {
+	t.Run(
+		"closes closers and groups",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var testCloser = &TestCloser{}
+			var groupCloser = &TestCloser{}
+			c.RegisterCloser(testCloser)
+			c.Group("foo").RegisterCloser(groupCloser)
+
+			require.Nil(t, c.Close(context.Background()))
+			require.True(t, testCloser.closed)
+			require.True(t, groupCloser.closed)
+
+			select {
+			case <-c.Done():
+			default:
+				t.Fatal("done channel should be closed")
+			}
+			require.Nil(t, c.Err())
+		},
+	)
+
+	t.Run(
+		"closer error",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.RegisterCloser(&TestCloser{err: errors.New("foo")})
+
+			require.NotNil(t, c.Close(context.Background()))
+			require.NotNil(t, c.Err())
+		},
+	)
+
+	t.Run(
+		"waits for watchers",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var c = New(DefaultConfig())
+			var mockW = NewMockWatcher(ctrl)
+			var mockL = NewMockLoader(ctrl)
+			var d = make(chan struct{})
+
+			mockL.EXPECT().Name().AnyTimes().Return("l")
+			mockW.EXPECT().Start().Return(nil)
+			mockW.EXPECT().Watch().AnyTimes().Return(make(chan struct{}))
+			mockW.EXPECT().Done().AnyTimes().Return(d)
+			mockW.EXPECT().Close().Return(nil)
+
+			c.RegisterLoaderWatcher(NewLoaderWatcher(mockL, mockW))
+			require.Nil(t, c.Watch())
+
+			var ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			require.Nil(t, c.Close(ctx))
+			// closing twice is a noop
+			require.Nil(t, c.Close(ctx))
+		},
+	)
+
+	t.Run(
+		"context done",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			// simulate a reload which never returns
+			c.wg.Add(1)
+
+			var ctx, cancel = context.WithCancel(context.Background())
+			cancel()
+
+			require.Equal(t, context.Canceled, c.Close(ctx))
+		},
+	)
+
+	t.Run(
+		"on fatal",
+		func(t *testing.T) {
+			var fatalErr error
+			var cfg = DefaultConfig()
+			cfg.OnFatal = func(err error) {
+				fatalErr = err
+			}
+			var c = New(cfg)
+			c.RegisterLoader(
+				&DummyLoader{
+					err:           true,
+					stopOnFailure: true,
+				},
+			)
+
+			require.NotNil(t, c.Load())
+			require.NotNil(t, fatalErr)
+			<-c.Done()
+			require.NotNil(t, c.Err())
+		},
+	)
+}
+
 func TestGet(t *testing.T) {
 	reset()
 	Init(DefaultConfig())
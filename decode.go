@@ -0,0 +1,222 @@
+package konfig
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// ErrDecodeMsg is the error message returned when a config value cannot be decoded into the bound value
+var ErrDecodeMsg = "Err config '%s' cannot be decoded"
+
+// Decoder is the interface a type can implement to decode itself from a config value when bound to a store
+type Decoder interface {
+	// Decode decodes the config value v
+	Decode(v interface{}) error
+}
+
+var (
+	decoderType         = reflect.TypeOf((*Decoder)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+	urlType             = reflect.TypeOf(url.URL{})
+	locationType        = reflect.TypeOf(time.Location{})
+	locationPtrType     = reflect.TypeOf((*time.Location)(nil))
+)
+
+// isLeaf checks whether the type t is decoded from a single config value rather than from sub keys
+func isLeaf(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || t == urlType || t == locationType {
+		return true
+	}
+	var pt = reflect.PtrTo(t)
+	return pt.Implements(decoderType) || pt.Implements(textUnmarshalerType)
+}
+
+// decode decodes the config value v into a value of type t
+func (val *value) decode(t reflect.Type, v interface{}) (reflect.Value, error) {
+	if v == nil {
+		return reflect.Zero(t), nil
+	}
+
+	var vt = reflect.TypeOf(v)
+	if vt.AssignableTo(t) {
+		return reflect.ValueOf(v), nil
+	}
+
+	// a location is only used through a pointer, copying it would lose
+	// the identity of the Local and UTC locations
+	if t == locationPtrType {
+		var loc, err = time.LoadLocation(cast.ToString(v))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(loc), nil
+	}
+
+	// pointers are decoded from the value they point to
+	if t.Kind() == reflect.Ptr {
+		var nv, err = val.decode(t.Elem(), v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		var p = reflect.New(t.Elem())
+		p.Elem().Set(nv)
+		return p, nil
+	}
+
+	var p = reflect.New(t)
+	if d, ok := p.Interface().(Decoder); ok {
+		if err := d.Decode(v); err != nil {
+			return reflect.Value{}, err
+		}
+		return p.Elem(), nil
+	}
+
+	switch t {
+	case timeType:
+		// time.Time implements TextUnmarshaler which only accepts RFC 3339,
+		// cast accepts other layouts such as dates
+		var r, err = cast.ToTimeE(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(r), nil
+	case urlType:
+		var u, err = url.Parse(cast.ToString(v))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(*u), nil
+	case locationType:
+		return reflect.Value{}, fmt.Errorf("cannot decode %T into %s, use *%s", v, t, t)
+	}
+
+	if u, ok := p.Interface().(encoding.TextUnmarshaler); ok {
+		var text, err = cast.ToStringE(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if err := u.UnmarshalText([]byte(text)); err != nil {
+			return reflect.Value{}, err
+		}
+		return p.Elem(), nil
+	}
+
+	// types supported by castValue
+	var zero = reflect.Zero(t).Interface()
+	if r, err := castValueE(zero, v); err != nil {
+		return reflect.Value{}, err
+	} else if rt := reflect.TypeOf(r); rt == t {
+		return reflect.ValueOf(r), nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		var r, err = cast.ToStringE(v)
+		return convertValue(r, t, err)
+	case reflect.Bool:
+		var r, err = cast.ToBoolE(v)
+		return convertValue(r, t, err)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var r, err = cast.ToInt64E(v)
+		return convertValue(r, t, err)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var r, err = cast.ToUint64E(v)
+		return convertValue(r, t, err)
+	case reflect.Float32, reflect.Float64:
+		var r, err = cast.ToFloat64E(v)
+		return convertValue(r, t, err)
+	case reflect.Slice:
+		return val.decodeSlice(t, v)
+	case reflect.Map:
+		return val.decodeMap(t, v)
+	case reflect.Struct:
+		return val.decodeStruct(t, v)
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot decode %T into %s", v, t)
+}
+
+func convertValue(r interface{}, t reflect.Type, err error) (reflect.Value, error) {
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(r).Convert(t), nil
+}
+
+func (val *value) decodeSlice(t reflect.Type, v interface{}) (reflect.Value, error) {
+	var rv = reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return reflect.Value{}, fmt.Errorf("cannot decode %T into %s", v, t)
+	}
+
+	var res = reflect.MakeSlice(t, rv.Len(), rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		var nv, err = val.decode(t.Elem(), rv.Index(i).Interface())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("index %d: %v", i, err)
+		}
+		res.Index(i).Set(nv)
+	}
+	return res, nil
+}
+
+func (val *value) decodeMap(t reflect.Type, v interface{}) (reflect.Value, error) {
+	var m, err = cast.ToStringMapE(v)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	var res = reflect.MakeMapWithSize(t, len(m))
+	for k, vv := range m {
+		var nk, err = val.decode(t.Key(), k)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("key %s: %v", k, err)
+		}
+		nv, err := val.decode(t.Elem(), vv)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("key %s: %v", k, err)
+		}
+		res.SetMapIndex(nk, nv)
+	}
+	return res, nil
+}
+
+// decodeStruct decodes a map into a struct, nested maps are flattened into keys and set on the struct
+func (val *value) decodeStruct(t reflect.Type, v interface{}) (reflect.Value, error) {
+	var m, err = cast.ToStringMapE(v)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	var nVal = reflect.New(t)
+	for k, vv := range flattenMap(m, "") {
+		if _, err := val.setStruct(k, vv, nVal); err != nil {
+			return reflect.Value{}, fmt.Errorf("key %s: %v", k, err)
+		}
+	}
+	return nVal.Elem(), nil
+}
+
+// flattenMap flattens nested maps into keys separated by KeySep
+func flattenMap(m map[string]interface{}, prefix string) map[string]interface{} {
+	var res = make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if nm, err := cast.ToStringMapE(v); err == nil && v != nil && reflect.TypeOf(v).Kind() == reflect.Map {
+			for kk, vv := range flattenMap(nm, prefix+k+KeySep) {
+				res[kk] = vv
+			}
+			continue
+		}
+		res[prefix+k] = v
+	}
+	return res
+}
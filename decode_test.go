@@ -0,0 +1,156 @@
+package konfig
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+type csv []string
+
+func (c *csv) Decode(v interface{}) error {
+	var s, ok = v.(string)
+	if !ok {
+		return errors.New("not a string")
+	}
+	*c = strings.Split(s, ",")
+	return nil
+}
+
+type Endpoint struct {
+	Host string `konfig:"host"`
+	Port int    `konfig:"port"`
+	TLS  struct {
+		Enabled bool `konfig:"enabled"`
+	} `konfig:"tls"`
+}
+
+func TestDecode(t *testing.T) {
+	type Config struct {
+		Endpoints []Endpoint         `konfig:"endpoints"`
+		Limits    map[string]int     `konfig:"limits"`
+		Weights   map[string]float64 `konfig:"weights"`
+		IP        net.IP             `konfig:"ip"`
+		URL       *url.URL           `konfig:"url"`
+		Location  *time.Location     `konfig:"location"`
+		Date      time.Time          `konfig:"date"`
+		Level     level              `konfig:"level"`
+		Tags      csv                `konfig:"tags"`
+		Name      string             `konfig:"name"`
+	}
+
+	var cfg = DefaultConfig()
+	cfg.StrictBinding = true
+	var c = New(cfg)
+	c.Bind(Config{})
+
+	var l = &valuesLoader{values: Values{
+		"endpoints": []interface{}{
+			map[interface{}]interface{}{"host": "a", "port": 80, "tls": map[interface{}]interface{}{"enabled": true}},
+			map[string]interface{}{"host": "b", "port": "81"},
+		},
+		"limits":    map[string]interface{}{"a": "1", "b": 2},
+		"weights.x": "0.5",
+		"ip":        "127.0.0.1",
+		"url":       "http://localhost:8080/path",
+		"location":  "Local",
+		"date":      "2020-01-02",
+		"level":     "info",
+		"tags":      "a,b",
+		"name":      "konfig",
+	}}
+	var cl = c.RegisterLoader(l)
+	require.Nil(t, c.Load())
+
+	var v = c.Value().(Config)
+	require.Len(t, v.Endpoints, 2)
+	require.Equal(t, "a", v.Endpoints[0].Host)
+	require.Equal(t, 80, v.Endpoints[0].Port)
+	require.True(t, v.Endpoints[0].TLS.Enabled)
+	require.Equal(t, "b", v.Endpoints[1].Host)
+	require.Equal(t, 81, v.Endpoints[1].Port)
+	require.Equal(t, map[string]int{"a": 1, "b": 2}, v.Limits)
+	require.Equal(t, map[string]float64{"x": 0.5}, v.Weights)
+	require.Equal(t, net.ParseIP("127.0.0.1"), v.IP)
+	require.Equal(t, "localhost:8080", v.URL.Host)
+	require.True(t, v.Location == time.Local)
+	require.Equal(t, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), v.Date)
+	require.Equal(t, level(1), v.Level)
+	require.Equal(t, csv{"a", "b"}, v.Tags)
+
+	var testCases = []struct {
+		key   string
+		value interface{}
+	}{
+		{key: "endpoints", value: "a"},
+		{key: "limits", value: map[string]interface{}{"a": "abc"}},
+		{key: "ip", value: "abc"},
+		{key: "location", value: "Nowhere/Nowhere"},
+		{key: "date", value: "not a date"},
+		{key: "level", value: "trace"},
+		{key: "tags", value: 1},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(
+			testCase.key,
+			func(t *testing.T) {
+				var values = Values{"name": "changed"}
+				values[testCase.key] = testCase.value
+				l.values = values
+
+				var err = c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0)
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), "Err config '"+testCase.key+"' cannot be decoded")
+
+				// the previous values are kept
+				require.Equal(t, "konfig", c.Value().(Config).Name)
+				require.Equal(t, "konfig", c.String("name"))
+			},
+		)
+	}
+	// without StrictBinding the error is logged and the other values are loaded
+	c.cfg.StrictBinding = false
+	l.values = Values{"name": "changed", "ip": "abc"}
+	require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+	require.Equal(t, "changed", c.Value().(Config).Name)
+	require.Nil(t, c.Value().(Config).IP)
+	require.Equal(t, "abc", c.String("ip"))
+}
+
+func TestDecodeLocationValue(t *testing.T) {
+	type Config struct {
+		Location time.Location `konfig:"location"`
+	}
+
+	var cfg = DefaultConfig()
+	cfg.StrictBinding = true
+	var c = New(cfg)
+	c.Bind(Config{})
+	c.RegisterLoader(&valuesLoader{values: Values{"location": "UTC"}})
+
+	// a location can only be bound through a pointer
+	var err = c.Load()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "Err config 'location' cannot be decoded")
+}
@@ -1,5 +1,7 @@
 package konfig
 
+import "sort"
+
 // Group gets a group of configs
 func Group(groupName string) Store {
 	return instance().Group(groupName)
@@ -10,6 +12,24 @@ func (c *S) Group(groupName string) Store {
 	return c.lazyGroup(groupName)
 }
 
+// Groups returns the sorted names of the groups of the global store
+func Groups() []string {
+	return instance().Groups()
+}
+
+// Groups returns the sorted names of the child groups of the store
+func (c *S) Groups() []string {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var names = make([]string, 0, len(c.groups))
+	for name := range c.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (c *S) lazyGroup(groupName string) Store {
 	c.mut.Lock()
 	defer c.mut.Unlock()
@@ -21,4 +21,16 @@ func TestGroup(t *testing.T) {
 			require.Equal(t, "bar", gg.Get("foo"))
 		},
 	)
+
+	t.Run(
+		"groups",
+		func(t *testing.T) {
+			var c = newStore(DefaultConfig())
+			require.Equal(t, []string{}, c.Groups())
+
+			c.Group("b")
+			c.Group("a")
+			require.Equal(t, []string{"a", "b"}, c.Groups())
+		},
+	)
 }
@@ -0,0 +1,233 @@
+package konfig
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// redactedErrorMsg is the error served by HealthHandler and ReadyHandler instead of the errors of loaders,
+// watchers and checks, which may contain values of the store
+const redactedErrorMsg = "Err redacted, see the logs of the store"
+
+// LoaderHealth is the health of a loader of a store
+type LoaderHealth struct {
+	// Name is the name of the loader
+	Name string `json:"name"`
+	// LastLoad is the time of the last successful load of the loader
+	LastLoad *time.Time `json:"lastLoad,omitempty"`
+	// LastError is the error of the last failed load of the loader
+	LastError string `json:"lastError,omitempty"`
+	// LastErrorAt is the time of the last failed load of the loader
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+	// ConsecutiveFailures is the number of loads which failed since the last successful load
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// Watched is false if the loader has no watcher, the watcher fields are then left empty
+	Watched bool `json:"watched"`
+	// Watching is true if the watcher of the loader is running
+	Watching bool `json:"watching"`
+	// WatcherClosed is true if the watcher of the loader is done
+	WatcherClosed bool `json:"watcherClosed"`
+	// WatcherError is the error of the watcher of the loader if it is done
+	WatcherError string `json:"watcherError,omitempty"`
+	// PanicRestarts is the number of times the watcher of the loader has been restarted after a panic
+	PanicRestarts int `json:"panicRestarts"`
+	// Stale is true if the loader failed and the store serves the fallback values of the loader
+	Stale bool `json:"stale"`
+	// StaleSince is the time at which the loader started serving stale values
+	StaleSince *time.Time `json:"staleSince,omitempty"`
+	// StaleError is the error which made the loader serve stale values
+	StaleError string `json:"staleError,omitempty"`
+}
+
+// StoreHealth is the health of a store
+type StoreHealth struct {
+	// Name is the name of the store
+	Name string `json:"name"`
+	// Healthy is true if the store is not closed and none of the watchers of its loaders failed
+	Healthy bool `json:"healthy"`
+	// Ready is true if the store is healthy, it has been loaded, all its loaders loaded successfully at least once,
+	// none of them serves stale values and its values are not invalid
+	Ready bool `json:"ready"`
+	// Closed is true if the store is closed
+	Closed bool `json:"closed"`
+	// Stale is true if at least one loader serves stale values
+	Stale bool `json:"stale"`
+	// Invalid is true if the values of the store failed the checks but were committed nonetheless,
+	// which happens when an override expires. It is reset once values passing the checks are committed.
+	Invalid bool `json:"invalid"`
+	// InvalidSince is the time at which the invalid values were committed
+	InvalidSince *time.Time `json:"invalidSince,omitempty"`
+	// InvalidError is the error of the checks of the invalid values
+	InvalidError string `json:"invalidError,omitempty"`
+	// Loaders is the health of the loaders of the store, in registration order
+	Loaders []LoaderHealth `json:"loaders"`
+}
+
+// Health returns the health of the global store
+func Health() StoreHealth {
+	return instance().Health()
+}
+
+// Health returns the health of the store and of its loaders
+func (c *S) Health() StoreHealth {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var h = StoreHealth{
+		Name:    c.name,
+		Healthy: true,
+		Loaders: make([]LoaderHealth, 0, len(c.WatcherLoaders)),
+	}
+
+	select {
+	case <-c.quit:
+		h.Closed = true
+		h.Healthy = false
+	default:
+	}
+
+	// the store is ready once every loader has loaded successfully
+	var loaded = true
+	for _, wl := range c.WatcherLoaders {
+		if wl.lastLoadAt.IsZero() {
+			loaded = false
+		}
+		var lh = LoaderHealth{
+			Name:                wl.Name(),
+			ConsecutiveFailures: wl.failures,
+			Watched:             watched(wl.Watcher),
+			Watching:            wl.watching,
+			PanicRestarts:       wl.restarts,
+		}
+		if !wl.lastLoadAt.IsZero() {
+			var t = wl.lastLoadAt
+			lh.LastLoad = &t
+		}
+		if wl.lastErr != nil {
+			var t = wl.lastErrAt
+			lh.LastError = wl.lastErr.Error()
+			lh.LastErrorAt = &t
+		}
+		// the done channel of a NopWatcher is always closed
+		if lh.Watched {
+			select {
+			case <-wl.Done():
+				lh.WatcherClosed = true
+				if err := wl.Err(); err != nil {
+					lh.WatcherError = err.Error()
+					h.Healthy = false
+				}
+			default:
+			}
+		}
+		if wl.stale != nil {
+			var t = wl.staleAt
+			lh.Stale = true
+			lh.StaleSince = &t
+			lh.StaleError = wl.stale.Error()
+			h.Stale = true
+		}
+		h.Loaders = append(h.Loaders, lh)
+	}
+
+	if c.invalid != nil {
+		var t = c.invalidAt
+		h.Invalid = true
+		h.InvalidSince = &t
+		h.InvalidError = c.invalid.Error()
+	}
+
+	// a store serving stale or invalid values is not ready, the stale loaders and the invalid error tell why
+	h.Ready = h.Healthy && c.loaded && loaded && !h.Stale && !h.Invalid
+
+	return h
+}
+
+// watched checks whether w watches a loader, it is false for a NopWatcher
+func watched(w Watcher) bool {
+	switch w := w.(type) {
+	case nil, NopWatcher, *NopWatcher:
+		return false
+	case *CacheLoader:
+		return watched(w.Watcher)
+	}
+	return true
+}
+
+// recordLoad records the result of a load of the loader wl for its health,
+// loads aborted because ctx is done are not recorded. A load serving stale values is a failed load.
+func (c *S) recordLoad(ctx context.Context, wl *loaderWatcher, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if err == nil {
+		err = wl.stale
+	}
+	if err != nil {
+		wl.lastErr = err
+		wl.lastErrAt = time.Now()
+		wl.failures++
+		return
+	}
+	wl.lastLoadAt = time.Now()
+	wl.failures = 0
+}
+
+// setWatching records whether the watcher of the loader wl is running
+func (c *S) setWatching(wl *loaderWatcher, watching bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	wl.watching = watching
+}
+
+// HealthHandler returns an http.Handler serving the health of the store s as JSON.
+// It responds with the status 503 if the store is not healthy, it can be used as a liveness probe.
+// Errors are redacted as they may contain values of the store, Health returns them.
+func HealthHandler(s Store) http.Handler {
+	return healthHandler(s, func(h StoreHealth) bool { return h.Healthy })
+}
+
+// ReadyHandler returns an http.Handler serving the health of the store s as JSON.
+// It responds with the status 503 if the store is not ready, it can be used as a readiness probe.
+// Errors are redacted as they may contain values of the store, Health returns them.
+func ReadyHandler(s Store) http.Handler {
+	return healthHandler(s, func(h StoreHealth) bool { return h.Ready })
+}
+
+func healthHandler(s Store, ok func(StoreHealth) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var h = s.Health()
+		h.redact()
+
+		w.Header().Set("Content-Type", "application/json")
+		if ok(h) {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(h)
+	})
+}
+
+// redact replaces the errors of the health h with redactedErrorMsg
+func (h *StoreHealth) redact() {
+	var redact = func(err *string) {
+		if *err != "" {
+			*err = redactedErrorMsg
+		}
+	}
+
+	redact(&h.InvalidError)
+	for i := range h.Loaders {
+		redact(&h.Loaders[i].LastError)
+		redact(&h.Loaders[i].WatcherError)
+		redact(&h.Loaders[i].StaleError)
+	}
+}
@@ -0,0 +1,243 @@
+package konfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrInterpolationMsg is the error message returned when a reference in a config value cannot be resolved
+	ErrInterpolationMsg = "Err config '%s' cannot resolve reference '%s'"
+	// ErrInterpolationCycleMsg is the error message returned when references in config values form a cycle
+	ErrInterpolationCycleMsg = "Err config '%s' has a reference cycle: %s"
+)
+
+const (
+	refEnvPrefix  = "env:"
+	refFilePrefix = "file:"
+	refDefaultSep = ":-"
+)
+
+// interpolator resolves the references in the values of a map
+type interpolator struct {
+	key      func(k string) string
+	env      func(name string) (string, bool)
+	file     func(p string) (string, bool)
+	raw      s
+	resolved s
+	path     []string
+}
+
+// interpolate returns the values of raw with the references ${key}, ${env:NAME} and ${file:/path} resolved
+// if interpolation is enabled, else it returns raw.
+func (c *S) interpolate(raw s) (s, error) {
+	if !c.cfg.Interpolation {
+		return raw, nil
+	}
+
+	var keys = make([]string, 0, len(raw))
+	for k := range raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var ip = &interpolator{
+		key:      c.canonical,
+		env:      c.lookupEnv,
+		file:     c.readFile,
+		raw:      raw,
+		resolved: make(s, len(raw)),
+	}
+	for _, k := range keys {
+		if _, err := ip.resolve(k); err != nil {
+			return nil, err
+		}
+	}
+	return ip.resolved, nil
+}
+
+// resolve returns the value of the key k with its references resolved
+func (ip *interpolator) resolve(k string) (interface{}, error) {
+	if v, ok := ip.resolved[k]; ok {
+		return v, nil
+	}
+
+	for i, kk := range ip.path {
+		if kk == k {
+			var cycle = append(append([]string{}, ip.path[i:]...), k)
+			return nil, fmt.Errorf(ErrInterpolationCycleMsg, k, strings.Join(cycle, " -> "))
+		}
+	}
+
+	var v = ip.raw[k]
+	var str, ok = v.(string)
+	if !ok || !strings.Contains(str, "${") {
+		ip.resolved[k] = v
+		return v, nil
+	}
+
+	ip.path = append(ip.path, k)
+	var r, err = ip.expand(k, str)
+	ip.path = ip.path[:len(ip.path)-1]
+	if err != nil {
+		return nil, err
+	}
+
+	ip.resolved[k] = r
+	return r, nil
+}
+
+// expand resolves the references in the value v of the key k.
+// If v is a single reference, the referenced value is returned as is, else references are formatted in the string.
+// $${ is an escaped ${.
+func (ip *interpolator) expand(k, v string) (interface{}, error) {
+	if strings.HasPrefix(v, "${") && strings.Index(v, "}") == len(v)-1 {
+		return ip.ref(k, v[2:len(v)-1])
+	}
+
+	var b strings.Builder
+	for {
+		var i = strings.Index(v, "${")
+		if i < 0 {
+			b.WriteString(v)
+			break
+		}
+		if i > 0 && v[i-1] == '$' {
+			b.WriteString(v[:i-1])
+			b.WriteString("${")
+			v = v[i+2:]
+			continue
+		}
+
+		var j = strings.Index(v[i:], "}")
+		if j < 0 {
+			return nil, fmt.Errorf(ErrInterpolationMsg, k, v[i:])
+		}
+
+		var r, err = ip.ref(k, v[i+2:i+j])
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(v[:i])
+		b.WriteString(fmt.Sprint(r))
+		v = v[i+j+1:]
+	}
+
+	return b.String(), nil
+}
+
+// ref returns the value of the reference ref in the value of the key k,
+// if the reference cannot be resolved its default value is returned.
+func (ip *interpolator) ref(k, ref string) (interface{}, error) {
+	var def string
+	var hasDef bool
+	if i := strings.Index(ref, refDefaultSep); i >= 0 {
+		ref, def, hasDef = ref[:i], ref[i+len(refDefaultSep):], true
+	}
+
+	switch {
+	case strings.HasPrefix(ref, refEnvPrefix):
+		if v, ok := ip.env(ref[len(refEnvPrefix):]); ok {
+			return v, nil
+		}
+	case strings.HasPrefix(ref, refFilePrefix):
+		if v, ok := ip.file(ref[len(refFilePrefix):]); ok {
+			return v, nil
+		}
+	default:
+		ref = ip.key(ref)
+		if _, ok := ip.raw[ref]; ok {
+			return ip.resolve(ref)
+		}
+	}
+
+	if hasDef {
+		return def, nil
+	}
+	return nil, fmt.Errorf(ErrInterpolationMsg, k, ref)
+}
+
+// keyRefs returns the keys referenced in the value v and whether v references env vars or files,
+// which are not returned as keys
+func keyRefs(v interface{}) ([]string, bool) {
+	var str, ok = v.(string)
+	if !ok {
+		return nil, false
+	}
+
+	var refs []string
+	var external bool
+	for {
+		var i = strings.Index(str, "${")
+		if i < 0 {
+			return refs, external
+		}
+		// $${ is an escaped ${
+		if i > 0 && str[i-1] == '$' {
+			str = str[i+2:]
+			continue
+		}
+		var j = strings.Index(str[i:], "}")
+		if j < 0 {
+			return refs, external
+		}
+		var ref = str[i+2 : i+j]
+		str = str[i+j+1:]
+
+		if d := strings.Index(ref, refDefaultSep); d >= 0 {
+			ref = ref[:d]
+		}
+		if strings.HasPrefix(ref, refEnvPrefix) || strings.HasPrefix(ref, refFilePrefix) {
+			external = true
+			continue
+		}
+		refs = append(refs, ref)
+	}
+}
+
+// lookupEnv returns the value of the environment variable name referenced in a value.
+// Only the InterpolationEnvVars can be read, as values of remote loaders could reference any variable.
+func (c *S) lookupEnv(name string) (string, bool) {
+	for _, n := range c.cfg.InterpolationEnvVars {
+		if n == name {
+			return os.LookupEnv(name)
+		}
+	}
+	return "", false
+}
+
+// readFile returns the content of the file p referenced in a value without its trailing new lines.
+// Only files in the InterpolationFileDirs can be read, as values of remote loaders could reference any local file.
+func (c *S) readFile(p string) (string, bool) {
+	var path, err = filepath.EvalSymlinks(p)
+	if err != nil {
+		return "", false
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return "", false
+	}
+
+	for _, d := range c.cfg.InterpolationFileDirs {
+		var dir, err = filepath.EvalSymlinks(d)
+		if err != nil {
+			continue
+		}
+		if dir, err = filepath.Abs(dir); err != nil {
+			continue
+		}
+		var rel string
+		if rel, err = filepath.Rel(dir, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		var b []byte
+		if b, err = os.ReadFile(path); err != nil {
+			return "", false
+		}
+		return strings.TrimRight(string(b), "\r\n"), true
+	}
+	return "", false
+}
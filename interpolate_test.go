@@ -0,0 +1,181 @@
+package konfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	var dir = t.TempDir()
+	var file = filepath.Join(dir, "password")
+	require.Nil(t, os.WriteFile(file, []byte("secret\n"), 0600))
+	var outside = filepath.Join(t.TempDir(), "password")
+	require.Nil(t, os.WriteFile(outside, []byte("secret\n"), 0600))
+	os.Setenv("KONFIG_TEST_INTERPOLATE", "env")
+	defer os.Unsetenv("KONFIG_TEST_INTERPOLATE")
+	os.Setenv("KONFIG_TEST_DENIED", "denied")
+	defer os.Unsetenv("KONFIG_TEST_DENIED")
+
+	var testCases = []struct {
+		name     string
+		raw      s
+		expected s
+		err      bool
+	}{
+		{
+			name: "key references",
+			raw: s{
+				"db.host": "localhost",
+				"db.port": 5432,
+				"db.url":  "postgres://${db.host}:${db.port}",
+				"port":    "${db.port}",
+				"other":   1,
+			},
+			expected: s{
+				"db.host": "localhost",
+				"db.port": 5432,
+				"db.url":  "postgres://localhost:5432",
+				"port":    5432,
+				"other":   1,
+			},
+		},
+		{
+			name: "nested references",
+			raw: s{
+				"a": "${b}/a",
+				"b": "${c}/b",
+				"c": "c",
+			},
+			expected: s{
+				"a": "c/b/a",
+				"b": "c/b",
+				"c": "c",
+			},
+		},
+		{
+			name: "env, file and defaults",
+			raw: s{
+				"env":      "${env:KONFIG_TEST_INTERPOLATE}",
+				"file":     "${file:" + file + "}",
+				"default":  "${missing:-foo}",
+				"empty":    "${env:KONFIG_TEST_MISSING:-}",
+				"escaped":  "$${foo}",
+				"embedded": "a-${env:KONFIG_TEST_INTERPOLATE}-b",
+			},
+			expected: s{
+				"env":      "env",
+				"file":     "secret",
+				"default":  "foo",
+				"empty":    "",
+				"escaped":  "${foo}",
+				"embedded": "a-env-b",
+			},
+		},
+		{
+			name: "file outside of the allowed directories",
+			raw:  s{"file": "${file:" + outside + "}"},
+			err:  true,
+		},
+		{
+			name: "file outside of the allowed directories with a default",
+			raw: s{
+				"file": "${file:" + filepath.Join(dir, "..", filepath.Base(filepath.Dir(outside)), "password") + ":-foo}",
+			},
+			expected: s{
+				"file": "foo",
+			},
+		},
+		{
+			name: "env var not allowed",
+			raw:  s{"env": "${env:KONFIG_TEST_DENIED}"},
+			err:  true,
+		},
+		{
+			name: "env var not allowed with a default",
+			raw:  s{"env": "${env:KONFIG_TEST_DENIED:-foo}"},
+			expected: s{
+				"env": "foo",
+			},
+		},
+		{
+			name: "missing reference",
+			raw:  s{"a": "${b}"},
+			err:  true,
+		},
+		{
+			name: "unterminated reference",
+			raw:  s{"a": "foo ${b"},
+			err:  true,
+		},
+		{
+			name: "cycle",
+			raw: s{
+				"a": "${b}",
+				"b": "${c:-foo}",
+				"c": "x${a}",
+			},
+			err: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(
+			testCase.name,
+			func(t *testing.T) {
+				var cfg = DefaultConfig()
+				cfg.Interpolation = true
+				cfg.InterpolationFileDirs = []string{dir}
+				cfg.InterpolationEnvVars = []string{"KONFIG_TEST_INTERPOLATE", "KONFIG_TEST_MISSING"}
+				var c = New(cfg)
+
+				var nm, err = c.interpolate(testCase.raw)
+				if testCase.err {
+					require.NotNil(t, err)
+					return
+				}
+				require.Nil(t, err)
+				require.Equal(t, testCase.expected, nm)
+			},
+		)
+	}
+}
+
+func TestInterpolateLoad(t *testing.T) {
+	var cfg = DefaultConfig()
+	cfg.Interpolation = true
+	var c = New(cfg)
+	var l = &valuesLoader{values: Values{"db.host": "localhost", "url": "http://${db.host}"}}
+	var cl = c.RegisterLoader(l)
+	require.Nil(t, c.Load())
+	require.Equal(t, "http://localhost", c.String("url"))
+
+	var changes []ChangeSet
+	c.OnChange("url", func(cs ChangeSet) error {
+		changes = append(changes, cs)
+		return nil
+	})
+
+	l.values = Values{"db.host": "remote", "url": "http://${db.host}"}
+	require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+	require.Equal(t, "http://remote", c.String("url"))
+	require.Len(t, changes, 1)
+	require.Equal(t, []Change{{Key: "url", Old: "http://localhost", New: "http://remote"}}, changes[0].Updated)
+
+	// the references are resolved again when a value is set
+	c.Set("db.host", "other")
+	require.Equal(t, "http://other", c.String("url"))
+
+	// a cycle fails the load and the store is left untouched
+	l.values = Values{"db.host": "${url}", "url": "http://${db.host}"}
+	require.NotNil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+	require.Equal(t, "http://other", c.String("url"))
+
+	// a value set with references which cannot be resolved is not set
+	require.NotNil(t, c.Set("db.host", "${missing}"))
+	require.Equal(t, "other", c.String("db.host"))
+	require.Equal(t, "http://other", c.String("url"))
+}
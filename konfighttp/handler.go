@@ -0,0 +1,404 @@
+// Package konfighttp provides an http.Handler to inspect a konfig.Store and override its values at runtime.
+package konfighttp
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/lalamove/konfig"
+)
+
+const defaultMask = "******"
+
+var (
+	// ErrNotFound is the error returned when a key, a loader or a group does not exist
+	ErrNotFound = errors.New("Not found")
+	// ErrMethodNotAllowed is the error returned when a path does not accept the method of the request
+	ErrMethodNotAllowed = errors.New("Method not allowed")
+	// ErrUnauthorized is the error returned when a request is not authorized
+	ErrUnauthorized = errors.New("Unauthorized")
+	// ErrWritesDisabled is the error returned for requests modifying the store when no Authorize function is set
+	ErrWritesDisabled = errors.New("Writes are disabled")
+	// ErrReadsDisabled is the error returned for requests reading the store when no AuthorizeRead or Authorize function is set
+	ErrReadsDisabled = errors.New("Reads are disabled")
+	// ErrLoadFailed is the error returned instead of the error of a failed load, which may contain values of the store
+	ErrLoadFailed = errors.New("Load failed, see the logs of the store")
+	// ErrWatcherFailed is the error returned instead of the error of a failed watcher
+	ErrWatcherFailed = errors.New("Watcher failed, see the logs of the store")
+	// ErrValueRejected is the error returned instead of the error of the checks rejecting a value, which may contain values of the store
+	ErrValueRejected = errors.New("Value rejected, see the logs of the store")
+)
+
+// Config is the configuration of the Handler
+type Config struct {
+	// Store is the store served by the handler, default is the global store
+	Store konfig.Store
+	// Authorize authorizes the requests modifying the store: overrides and loads.
+	// If it is nil, those requests are refused.
+	Authorize func(r *http.Request) bool
+	// AuthorizeRead authorizes the requests reading the store, default is Authorize.
+	// If both are nil, those requests are refused as values and loaders are not meant to be public.
+	AuthorizeRead func(r *http.Request) bool
+	// Mask is the value displayed instead of the values of secret keys, default is "******"
+	Mask string
+}
+
+// Handler is an http.Handler serving the keys, values, origins and history of a store.
+// It serves the following paths:
+//
+//	GET  /keys?prefix=p         lists the keys and values of the store
+//	GET  /keys/{key}            returns the value, origin and history of a key
+//	PUT  /keys/{key}            sets the value of a key from a JSON body {"value": v}
+//	GET  /history               lists the snapshots kept in the history of the store
+//	GET  /loaders               lists the loaders and their health
+//	POST /loaders/{name}/load   loads the loader with the given name
+//	GET  /groups                lists the groups of the store
+//	     /groups/{group}/...    serves the same paths for a group
+type Handler struct {
+	cfg *Config
+}
+
+// New returns a new Handler with the given Config
+func New(cfg *Config) *Handler {
+	if cfg.Store == nil {
+		cfg.Store = konfig.Instance()
+	}
+	if cfg.Mask == "" {
+		cfg.Mask = defaultMask
+	}
+	return &Handler{
+		cfg: cfg,
+	}
+}
+
+// BearerToken returns an Authorize function accepting the requests with the header "Authorization: Bearer token"
+func BearerToken(token string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		var h = r.Header.Get("Authorization")
+		if !strings.HasPrefix(h, "Bearer ") {
+			return false
+		}
+		return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(h, "Bearer ")), []byte(token)) == 1
+	}
+}
+
+// Key is a key of the store with its value
+type Key struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Secret bool        `json:"secret"`
+}
+
+// KeyDetail is a key of the store with its value, its origin and the history of its value
+type KeyDetail struct {
+	Key
+	Origin  *konfig.Origin `json:"origin,omitempty"`
+	History []KeyVersion   `json:"history"`
+}
+
+// KeyVersion is the value of a key in a snapshot of the history of the store
+type KeyVersion struct {
+	Version   uint64      `json:"version"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Exists    bool        `json:"exists"`
+	Value     interface{} `json:"value,omitempty"`
+}
+
+// Version is a snapshot of the history of the store
+type Version struct {
+	Version   uint64    `json:"version"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+type setBody struct {
+	Value interface{} `json:"value"`
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// all GET requests read the store, the requests modifying it are authorized by their handler
+	if r.Method == http.MethodGet && !h.authorizeRead(w, r) {
+		return
+	}
+
+	var path = strings.Trim(r.URL.Path, "/")
+	var parts []string
+	if path != "" {
+		parts = strings.SplitN(path, "/", 3)
+	}
+	h.serve(w, r, h.cfg.Store, parts)
+}
+
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request, s konfig.Store, parts []string) {
+	if len(parts) == 0 {
+		parts = []string{"keys"}
+	}
+
+	switch parts[0] {
+	case "keys":
+		if len(parts) == 1 {
+			h.keys(w, r, s)
+			return
+		}
+		h.key(w, r, s, strings.Join(parts[1:], "/"))
+	case "history":
+		if len(parts) != 1 {
+			h.error(w, http.StatusNotFound, ErrNotFound)
+			return
+		}
+		h.history(w, r, s)
+	case "loaders":
+		h.loaders(w, r, s, parts[1:])
+	case "groups":
+		h.groups(w, r, s, parts[1:])
+	default:
+		h.error(w, http.StatusNotFound, ErrNotFound)
+	}
+}
+
+func (h *Handler) keys(w http.ResponseWriter, r *http.Request, s konfig.Store) {
+	if r.Method != http.MethodGet {
+		h.error(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+		return
+	}
+
+	var keys = s.Keys(r.URL.Query().Get("prefix"))
+	var res = make([]Key, 0, len(keys))
+	for _, k := range keys {
+		res = append(res, h.keyValue(s, k, s.Get(k)))
+	}
+	h.json(w, http.StatusOK, res)
+}
+
+func (h *Handler) key(w http.ResponseWriter, r *http.Request, s konfig.Store, k string) {
+	switch r.Method {
+	case http.MethodGet:
+		if !s.Exists(k) {
+			h.error(w, http.StatusNotFound, ErrNotFound)
+			return
+		}
+		h.json(w, http.StatusOK, h.keyDetail(s, k))
+	case http.MethodPut, http.MethodPost:
+		if !h.authorize(w, r) {
+			return
+		}
+
+		var b setBody
+		if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+			h.error(w, http.StatusBadRequest, err)
+			return
+		}
+		// the value is checked as a load, the errors of the checks are not returned as they may contain values
+		if err := s.Set(k, b.Value); err != nil {
+			h.error(w, http.StatusUnprocessableEntity, ErrValueRejected)
+			return
+		}
+		h.json(w, http.StatusOK, h.keyDetail(s, k))
+	default:
+		h.error(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+	}
+}
+
+func (h *Handler) history(w http.ResponseWriter, r *http.Request, s konfig.Store) {
+	if r.Method != http.MethodGet {
+		h.error(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+		return
+	}
+
+	var snapshots = s.History()
+	var res = make([]Version, 0, len(snapshots))
+	for _, sn := range snapshots {
+		res = append(res, Version{
+			Version:   sn.Version,
+			Hash:      sn.Hash,
+			CreatedAt: sn.CreatedAt,
+		})
+	}
+	h.json(w, http.StatusOK, res)
+}
+
+func (h *Handler) loaders(w http.ResponseWriter, r *http.Request, s konfig.Store, parts []string) {
+	switch {
+	case len(parts) == 0:
+		if r.Method != http.MethodGet {
+			h.error(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+			return
+		}
+		h.json(w, http.StatusOK, h.loadersHealth(s))
+	case len(parts) == 2 && parts[1] == "load":
+		if r.Method != http.MethodPost {
+			h.error(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+			return
+		}
+		if !h.authorize(w, r) {
+			return
+		}
+
+		var err = s.LoadLoaderContext(r.Context(), parts[0])
+		switch {
+		case errors.Is(err, konfig.ErrLoaderNotFound):
+			h.error(w, http.StatusNotFound, err)
+		case err != nil:
+			h.error(w, http.StatusInternalServerError, ErrLoadFailed)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		h.error(w, http.StatusNotFound, ErrNotFound)
+	}
+}
+
+func (h *Handler) groups(w http.ResponseWriter, r *http.Request, s konfig.Store, parts []string) {
+	if len(parts) == 0 {
+		if r.Method != http.MethodGet {
+			h.error(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed)
+			return
+		}
+		h.json(w, http.StatusOK, s.Groups())
+		return
+	}
+
+	// groups are never created by a request
+	for _, g := range s.Groups() {
+		if g == parts[0] {
+			var rest []string
+			if len(parts) > 1 {
+				rest = strings.SplitN(parts[1], "/", 3)
+			}
+			h.serve(w, r, s.Group(g), rest)
+			return
+		}
+	}
+	h.error(w, http.StatusNotFound, ErrNotFound)
+}
+
+// authorize checks that the request r is authorized to modify the store, else it writes the error
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if h.cfg.Authorize == nil {
+		h.error(w, http.StatusForbidden, ErrWritesDisabled)
+		return false
+	}
+	if !h.cfg.Authorize(r) {
+		h.error(w, http.StatusUnauthorized, ErrUnauthorized)
+		return false
+	}
+	return true
+}
+
+// authorizeRead checks that the request r is authorized to read the store, else it writes the error
+func (h *Handler) authorizeRead(w http.ResponseWriter, r *http.Request) bool {
+	var authorize = h.cfg.AuthorizeRead
+	if authorize == nil {
+		authorize = h.cfg.Authorize
+	}
+	if authorize == nil {
+		h.error(w, http.StatusForbidden, ErrReadsDisabled)
+		return false
+	}
+	if !authorize(r) {
+		h.error(w, http.StatusUnauthorized, ErrUnauthorized)
+		return false
+	}
+	return true
+}
+
+// loadersHealth returns the health of the loaders of the store without the text of their errors,
+// as errors of loaders and checks may contain values of the store
+func (h *Handler) loadersHealth(s konfig.Store) []konfig.LoaderHealth {
+	var loaders = s.Health().Loaders
+	for i := range loaders {
+		if loaders[i].LastError != "" {
+			loaders[i].LastError = ErrLoadFailed.Error()
+		}
+		if loaders[i].StaleError != "" {
+			loaders[i].StaleError = ErrLoadFailed.Error()
+		}
+		if loaders[i].WatcherError != "" {
+			loaders[i].WatcherError = ErrWatcherFailed.Error()
+		}
+	}
+	return loaders
+}
+
+// isSecret checks if the key k is secret or if one of its subkeys is, as the value of a parent key
+// such as an array holds the values of its subkeys
+func (h *Handler) isSecret(s konfig.Store, k string) bool {
+	if s.IsSecret(k) {
+		return true
+	}
+	for _, kk := range s.Keys(k) {
+		if kk != k && s.IsSecret(kk) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyValue returns the key k with the value v, masked if the key or one of its subkeys is secret
+func (h *Handler) keyValue(s konfig.Store, k string, v interface{}) Key {
+	var secret = h.isSecret(s, k)
+	if secret {
+		v = h.cfg.Mask
+	}
+	return Key{
+		Key:    k,
+		Value:  v,
+		Secret: secret,
+	}
+}
+
+// keyDetail returns the value, origin and history of the key k
+func (h *Handler) keyDetail(s konfig.Store, k string) KeyDetail {
+	var d = KeyDetail{
+		Key:     h.keyValue(s, k, s.Get(k)),
+		History: make([]KeyVersion, 0),
+	}
+	if o, ok := s.Origin(k); ok {
+		d.Origin = &o
+	}
+
+	// we keep the versions in which the value of the key changed
+	var prev *KeyVersion
+	for _, sn := range s.History() {
+		var kv = KeyVersion{
+			Version:   sn.Version,
+			CreatedAt: sn.CreatedAt,
+			Exists:    sn.Exists(k),
+			Value:     sn.Get(k),
+		}
+		if prev != nil && prev.Exists == kv.Exists && reflect.DeepEqual(prev.Value, kv.Value) {
+			continue
+		}
+		prev = &kv
+		d.History = append(d.History, kv)
+	}
+	if d.Secret {
+		for i := range d.History {
+			if d.History[i].Exists {
+				d.History[i].Value = h.cfg.Mask
+			}
+		}
+	}
+
+	return d
+}
+
+func (h *Handler) json(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (h *Handler) error(w http.ResponseWriter, status int, err error) {
+	h.json(w, status, errorBody{Error: err.Error()})
+}
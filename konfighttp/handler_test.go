@@ -0,0 +1,278 @@
+package konfighttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lalamove/konfig"
+	"github.com/stretchr/testify/require"
+)
+
+type valuesLoader struct {
+	name   string
+	values konfig.Values
+	err    error
+}
+
+func (l *valuesLoader) Name() string              { return l.name }
+func (l *valuesLoader) StopOnFailure() bool       { return false }
+func (l *valuesLoader) MaxRetry() int             { return 0 }
+func (l *valuesLoader) RetryDelay() time.Duration { return 0 }
+func (l *valuesLoader) Load(v konfig.Values) error {
+	if l.err != nil {
+		return l.err
+	}
+	for k, vv := range l.values {
+		v.Set(k, vv)
+	}
+	return nil
+}
+
+func newStore(t *testing.T) (konfig.Store, *valuesLoader) {
+	var s = konfig.New(konfig.DefaultConfig())
+	var l = &valuesLoader{
+		name:   "values",
+		values: konfig.Values{"db.host": "localhost", "db.password": "secret"},
+	}
+	s.RegisterLoader(l)
+	s.Secret("db.password")
+	require.Nil(t, s.Load())
+	return s, l
+}
+
+func serve(t *testing.T, h http.Handler, method, path, body string, v interface{}) *httptest.ResponseRecorder {
+	var r = httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer token")
+	var w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if v != nil {
+		require.Nil(t, json.Unmarshal(w.Body.Bytes(), v))
+	}
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	t.Run(
+		"list keys",
+		func(t *testing.T) {
+			var s, _ = newStore(t)
+			var h = New(&Config{Store: s, Authorize: BearerToken("token")})
+
+			var keys []Key
+			var w = serve(t, h, http.MethodGet, "/keys", "", &keys)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(
+				t,
+				[]Key{
+					{Key: "db.host", Value: "localhost"},
+					{Key: "db.password", Value: "******", Secret: true},
+				},
+				keys,
+			)
+
+			w = serve(t, h, http.MethodGet, "/keys?prefix=db.host", "", &keys)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Len(t, keys, 1)
+		},
+	)
+
+	t.Run(
+		"secret subkeys",
+		func(t *testing.T) {
+			var s = konfig.New(konfig.DefaultConfig())
+			s.RegisterLoader(&valuesLoader{
+				name: "values",
+				values: konfig.Values{
+					"servers":            []interface{}{map[string]interface{}{"host": "a", "password": "hunter2"}},
+					"servers.0.host":     "a",
+					"servers.0.password": "hunter2",
+				},
+			})
+			s.Secret("servers.0.password")
+			require.Nil(t, s.Load())
+			var h = New(&Config{Store: s, Authorize: BearerToken("token")})
+
+			// the value of a key with a secret subkey holds the secret
+			var keys []Key
+			var w = serve(t, h, http.MethodGet, "/keys", "", &keys)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(
+				t,
+				[]Key{
+					{Key: "servers", Value: "******", Secret: true},
+					{Key: "servers.0.host", Value: "a"},
+					{Key: "servers.0.password", Value: "******", Secret: true},
+				},
+				keys,
+			)
+
+			var d KeyDetail
+			w = serve(t, h, http.MethodGet, "/keys/servers", "", &d)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, "******", d.Value)
+			require.NotContains(t, w.Body.String(), "hunter2")
+		},
+	)
+
+	t.Run(
+		"key with origin and history",
+		func(t *testing.T) {
+			var s, l = newStore(t)
+			var h = New(&Config{Store: s, Authorize: BearerToken("token")})
+
+			l.values = konfig.Values{"db.host": "remote", "db.password": "secret2"}
+			require.Nil(t, s.LoadLoader("values"))
+
+			var d KeyDetail
+			var w = serve(t, h, http.MethodGet, "/keys/db.host", "", &d)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, "remote", d.Value)
+			require.Equal(t, "values", d.Origin.Loader)
+			require.Len(t, d.History, 2)
+			require.Equal(t, "localhost", d.History[0].Value)
+			require.Equal(t, "remote", d.History[1].Value)
+
+			w = serve(t, h, http.MethodGet, "/keys/db.password", "", &d)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, "******", d.Value)
+			require.Len(t, d.History, 2)
+			for _, v := range d.History {
+				require.Equal(t, "******", v.Value)
+			}
+
+			w = serve(t, h, http.MethodGet, "/keys/db.port", "", nil)
+			require.Equal(t, http.StatusNotFound, w.Code)
+
+			var versions []Version
+			w = serve(t, h, http.MethodGet, "/history", "", &versions)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Len(t, versions, 2)
+		},
+	)
+
+	t.Run(
+		"set",
+		func(t *testing.T) {
+			var s, _ = newStore(t)
+
+			// writes are disabled without Authorize
+			var w = serve(t, New(&Config{Store: s}), http.MethodPut, "/keys/db.port", `{"value": 5432}`, nil)
+			require.Equal(t, http.StatusForbidden, w.Code)
+
+			var h = New(&Config{Store: s, Authorize: BearerToken("other")})
+			w = serve(t, h, http.MethodPut, "/keys/db.port", `{"value": 5432}`, nil)
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+			require.False(t, s.Exists("db.port"))
+
+			h = New(&Config{Store: s, Authorize: BearerToken("token")})
+			w = serve(t, h, http.MethodPut, "/keys/db.port", `{"value": 5432}`, nil)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, 5432, s.Int("db.port"))
+
+			w = serve(t, h, http.MethodPut, "/keys/db.port", `{`, nil)
+			require.Equal(t, http.StatusBadRequest, w.Code)
+
+			// values rejected by the checks are not set and the errors are not returned
+			var sc = konfig.NewSchema()
+			sc.Key("db.port").Type(konfig.TypeInt)
+			s.RegisterValidator(sc)
+			var e errorBody
+			w = serve(t, h, http.MethodPut, "/keys/db.port", `{"value": "hunter2"}`, &e)
+			require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			require.Equal(t, ErrValueRejected.Error(), e.Error)
+			require.Equal(t, 5432, s.Int("db.port"))
+
+			// the value is kept when loaders which don't set the key reload
+			require.Nil(t, s.LoadLoader("values"))
+			require.Equal(t, 5432, s.Int("db.port"))
+		},
+	)
+
+	t.Run(
+		"read authorization",
+		func(t *testing.T) {
+			var s, _ = newStore(t)
+
+			// reads are disabled without AuthorizeRead or Authorize
+			var w = serve(t, New(&Config{Store: s}), http.MethodGet, "/keys", "", nil)
+			require.Equal(t, http.StatusForbidden, w.Code)
+
+			w = serve(t, New(&Config{Store: s, Authorize: BearerToken("other")}), http.MethodGet, "/keys", "", nil)
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+
+			// AuthorizeRead takes precedence over Authorize for reads only
+			var h = New(&Config{Store: s, Authorize: BearerToken("other"), AuthorizeRead: BearerToken("token")})
+			w = serve(t, h, http.MethodGet, "/loaders", "", nil)
+			require.Equal(t, http.StatusOK, w.Code)
+			w = serve(t, h, http.MethodPut, "/keys/db.port", `{"value": 5432}`, nil)
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+		},
+	)
+
+	t.Run(
+		"loaders",
+		func(t *testing.T) {
+			var s, l = newStore(t)
+			var h = New(&Config{Store: s, Authorize: BearerToken("token")})
+
+			var loaders []konfig.LoaderHealth
+			var w = serve(t, h, http.MethodGet, "/loaders", "", &loaders)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Len(t, loaders, 1)
+			require.Equal(t, "values", loaders[0].Name)
+
+			l.values = konfig.Values{"db.host": "remote"}
+			w = serve(t, h, http.MethodPost, "/loaders/values/load", "", nil)
+			require.Equal(t, http.StatusNoContent, w.Code)
+			require.Equal(t, "remote", s.String("db.host"))
+
+			w = serve(t, h, http.MethodPost, "/loaders/unknown/load", "", nil)
+			require.Equal(t, http.StatusNotFound, w.Code)
+
+			// errors are not returned as they may contain values
+			l.err = errors.New("cannot parse password=hunter2")
+			var e errorBody
+			w = serve(t, h, http.MethodPost, "/loaders/values/load", "", &e)
+			require.Equal(t, http.StatusInternalServerError, w.Code)
+			require.Equal(t, ErrLoadFailed.Error(), e.Error)
+
+			w = serve(t, h, http.MethodGet, "/loaders", "", &loaders)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, ErrLoadFailed.Error(), loaders[0].LastError)
+			require.NotContains(t, w.Body.String(), "hunter2")
+
+			w = serve(t, h, http.MethodGet, "/loaders/values/load", "", nil)
+			require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		},
+	)
+
+	t.Run(
+		"groups",
+		func(t *testing.T) {
+			var s, _ = newStore(t)
+			var g = s.Group("cache")
+			g.RegisterLoader(&valuesLoader{name: "cache", values: konfig.Values{"ttl": "1m"}})
+			require.Nil(t, g.Load())
+			var h = New(&Config{Store: s, Authorize: BearerToken("token")})
+
+			var groups []string
+			var w = serve(t, h, http.MethodGet, "/groups", "", &groups)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, []string{"cache"}, groups)
+
+			var d KeyDetail
+			w = serve(t, h, http.MethodGet, "/groups/cache/keys/ttl", "", &d)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, "1m", d.Value)
+
+			w = serve(t, h, http.MethodGet, "/groups/unknown/keys", "", nil)
+			require.Equal(t, http.StatusNotFound, w.Code)
+			require.Equal(t, []string{"cache"}, s.Groups())
+		},
+	)
+}
@@ -1,11 +1,13 @@
 package konfig
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -26,6 +28,36 @@ type Loader interface {
 	RetryDelay() time.Duration
 }
 
+// LoaderContext is a Loader which takes a context when loading. Cancelling the context
+// should abort the load.
+type LoaderContext interface {
+	Loader
+	// LoadContext loads config values in a Values, it returns when ctx is done
+	LoadContext(context.Context, Values) error
+}
+
+type loaderContext struct {
+	Loader
+}
+
+// NewLoaderContext returns a LoaderContext from the Loader l.
+// If l already implements LoaderContext it is returned as is,
+// else LoadContext checks that the context is not done and calls Load.
+func NewLoaderContext(l Loader) LoaderContext {
+	if lc, ok := l.(LoaderContext); ok {
+		return lc
+	}
+	return loaderContext{l}
+}
+
+// LoadContext implements LoaderContext
+func (l loaderContext) LoadContext(ctx context.Context, v Values) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return l.Load(v)
+}
+
 // LoaderHooks are functions ran when a config load has been performed
 type LoaderHooks []func(Store) error
 
@@ -46,9 +78,19 @@ func LoadWatch() error {
 
 // LoadWatch loads the config then starts watching it
 func (c *S) LoadWatch() error {
-	if err := c.Load(); err != nil {
+	return c.LoadWatchContext(context.Background())
+}
+
+// LoadWatchContext loads the config then starts watching it until ctx is done
+func LoadWatchContext(ctx context.Context) error {
+	return instance().LoadWatchContext(ctx)
+}
+
+// LoadWatchContext loads the config then starts watching it until ctx is done
+func (c *S) LoadWatchContext(ctx context.Context) error {
+	if err := c.LoadContext(ctx); err != nil {
 		return err
-	} else if err := c.Watch(); err != nil {
+	} else if err := c.WatchContext(ctx); err != nil {
 		return err
 	}
 	return nil
@@ -61,31 +103,164 @@ func Load() error {
 
 // Load is a function running load on the global config instance
 func (c *S) Load() error {
+	return c.LoadContext(context.Background())
+}
+
+// LoadContext runs load on the global config instance with the given context
+func LoadContext(ctx context.Context) error {
+	return instance().LoadContext(ctx)
+}
+
+// LoadContext loads all loaders registered in the store.
+// If ctx is done or the store is closed, retries are aborted and LoadContext returns a non nil error.
+func (c *S) LoadContext(ctx context.Context) error {
 	if len(c.WatcherLoaders) == 0 {
 		panic(ErrNoLoaders)
 	}
-	for _, l := range c.WatcherLoaders {
-		// we load the loader once, then we start the reload worker with the watcher
-		if err := c.loaderLoadRetry(l, 0); err != nil {
+
+	// in transactional mode all loaders are loaded in a single transaction
+	if c.cfg.Transactional {
+		return c.ReloadContext(ctx)
+	}
+
+	ctx, cancel := c.withQuit(ctx)
+	defer cancel()
+
+	// once the store has been loaded, every load is checked before it is committed, even if this one fails
+	defer c.setLoaded()
+
+	if c.cfg.ParallelLoad {
+		return c.loadParallel(ctx)
+	}
+
+	for i, l := range c.WatcherLoaders {
+		// we load the loader once, then we start the reload worker with the watcher.
+		// the values of all loaders are checked before the values of the last one are committed.
+		if err := c.loaderLoad(ctx, l, 0, i == len(c.WatcherLoaders)-1); err != nil {
 
 			// if loader says we should stop in failure, stop the world
 			// else just return the error
 			if l.StopOnFailure() {
-				c.stop()
+				c.stop(err)
 			}
 
 			return err
 		}
 	}
 
-	// now that we've loaded everything, let's check strict keys
-	if err := c.checkStrictKeys(); err != nil {
-		c.cfg.Logger.Get().Error("Error while checking strict keys: " + err.Error())
-		return err
+	return nil
+}
+
+// LoadLoader loads the loader with the given name in the global store
+func LoadLoader(name string) error {
+	return instance().LoadLoader(name)
+}
+
+// LoadLoader loads the loader with the given name
+func (c *S) LoadLoader(name string) error {
+	return c.LoadLoaderContext(context.Background(), name)
+}
+
+// LoadLoaderContext loads the loader with the given name in the global store with the given context
+func LoadLoaderContext(ctx context.Context, name string) error {
+	return instance().LoadLoaderContext(ctx, name)
+}
+
+// LoadLoaderContext loads the loader with the given name as a watcher reload does: values are checked and hooks are run.
+// A failure is returned and never stops the store. If no loader has the name, it returns ErrLoaderNotFound.
+func (c *S) LoadLoaderContext(ctx context.Context, name string) error {
+	for _, wl := range c.WatcherLoaders {
+		if wl.Name() != name {
+			continue
+		}
+
+		ctx, cancel := c.withQuit(ctx)
+		defer cancel()
+
+		return c.loaderLoadRetry(ctx, wl, 0)
 	}
-	c.loaded = true
+	return ErrLoaderNotFound
+}
 
-	return nil
+// loadParallel fetches all loaders concurrently then loads their values in registration order.
+// It returns the errors of all failing loaders.
+func (c *S) loadParallel(ctx context.Context) error {
+	var values, errs = c.fetchAll(ctx)
+
+	var multiErr error
+	var stop bool
+	for i, wl := range c.WatcherLoaders {
+		if errs[i] != nil {
+			c.recordLoad(ctx, wl, errs[i])
+			multiErr = multierror.Append(multiErr, errs[i])
+			stop = stop || wl.StopOnFailure()
+		}
+	}
+
+	// if a loader says we should stop in failure, stop the world
+	if stop {
+		c.stop(multiErr)
+		return multiErr
+	}
+
+	// the values of all loaders are checked before the values of the last one are committed
+	var last = -1
+	for i := range c.WatcherLoaders {
+		if errs[i] == nil {
+			last = i
+		}
+	}
+
+	for i, wl := range c.WatcherLoaders {
+		if errs[i] != nil {
+			continue
+		}
+		var err = c.loadValues(wl, values[i], i == last)
+		c.recordLoad(ctx, wl, err)
+		if err != nil {
+			multiErr = multierror.Append(multiErr, err)
+			if wl.StopOnFailure() {
+				c.stop(multiErr)
+				return multiErr
+			}
+		}
+	}
+
+	return multiErr
+}
+
+// fetchAll fetches all loaders, concurrently if ParallelLoad is enabled.
+// The values and errors are returned in registration order.
+func (c *S) fetchAll(ctx context.Context) ([]fetched, []error) {
+	var values = make([]fetched, len(c.WatcherLoaders))
+	var errs = make([]error, len(c.WatcherLoaders))
+
+	if !c.cfg.ParallelLoad {
+		for i, wl := range c.WatcherLoaders {
+			values[i], errs[i] = c.fetch(ctx, wl, 0)
+		}
+		return values, errs
+	}
+
+	var wg sync.WaitGroup
+	for i, wl := range c.WatcherLoaders {
+		wg.Add(1)
+		go func(i int, wl *loaderWatcher) {
+			defer wg.Done()
+			values[i], errs[i] = c.fetch(ctx, wl, 0)
+		}(i, wl)
+	}
+	wg.Wait()
+
+	return values, errs
+}
+
+// setLoaded marks the store as loaded, every following load checks the strict keys and validates the values before they are committed
+func (c *S) setLoaded() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.loaded = true
 }
 
 // ConfigLoader is a wrapper of Loader with methods to add hooks
@@ -120,59 +295,140 @@ func (cl *ConfigLoader) AddHooks(loaderHooks ...func(Store) error) *ConfigLoader
 	return cl
 }
 
+// withQuit returns a context which is cancelled when ctx is done or when the store is closed.
+// It starts a goroutine which returns only when the context is cancelled,
+// callers must call the returned cancel function once they are done with the context.
+func (c *S) withQuit(ctx context.Context) (context.Context, context.CancelFunc) {
+	var qCtx, cancel = context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-c.quit:
+		case <-qCtx.Done():
+		}
+		cancel()
+	}()
+	return qCtx, cancel
+}
+
+// fetch calls the loader wl and retries as long as its retry policy allows it, retry is the number of the first try.
 // We don't look for Done on the watcher here as the NopWatcher needs to run load at least once
-func (c *S) loaderLoadRetry(wl *loaderWatcher, retry int) error {
-	// we create a new Values
-	var v = make(Values, len(wl.values))
+func (c *S) fetch(ctx context.Context, wl *loaderWatcher, retry int) (fetched, error) {
+	var policy RetryPolicy
+	var start = time.Now()
 
-	// we call the loader
-	if err := wl.Load(v); err != nil {
+	for {
+		// the load has been aborted, we don't call the loader
+		if err := ctx.Err(); err != nil {
+			return fetched{}, err
+		}
 
+		// we create a new Values
+		var v = make(Values, len(wl.values))
+
+		// we call the loader
+		var err = wl.LoadContext(ctx, v)
+		if err == nil {
+			return fetched{values: v}, nil
+		}
+
+		// the load has been aborted, it is not an error of the loader
+		if ctx.Err() != nil {
+			return fetched{}, ctx.Err()
+		}
+
+		// the name is kept for the retry metric so that the loader is not asked for it again
+		var name = wl.Name()
 		c.cfg.Logger.Get().Error(fmt.Sprintf(
 			"Error %d in loader %s: %s",
 			retry,
-			wl.Name(),
+			name,
 			err.Error(),
 		))
 
-		if retry >= wl.MaxRetry() {
-			return err
+		if policy == nil {
+			policy = wl.policy()
+		}
+		var delay, ok = policy.Next(retry+1, time.Since(start), err)
+		if !ok {
+			return c.fallback(wl, err)
 		}
 
-		// wait before retrying
-		time.Sleep(wl.RetryDelay())
+		// wait before retrying unless the context is done
+		var t = time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return fetched{}, ctx.Err()
+		}
 
-		return c.loaderLoadRetry(wl, retry+1)
+		if c.cfg.Metrics && wl.metrics != nil {
+			c.metrics[MetricsConfigReload].(*prometheus.CounterVec).
+				WithLabelValues(metricsRetryLabel, c.name, name).
+				Inc()
+		}
+		retry++
 	}
+}
+
+func (c *S) loaderLoadRetry(ctx context.Context, wl *loaderWatcher, retry int) error {
+	return c.loaderLoad(ctx, wl, retry, false)
+}
 
+// loaderLoad fetches the loader wl and loads its values. If strict is true, the strict keys are checked
+// and the values are validated before they are committed even if the store has not been loaded yet.
+func (c *S) loaderLoad(ctx context.Context, wl *loaderWatcher, retry int, strict bool) error {
+	var f, err = c.fetch(ctx, wl, retry)
+	if err == nil {
+		err = c.loadValues(wl, f, strict)
+	}
+	c.recordLoad(ctx, wl, err)
+
+	return err
+}
+
+// loadValues adds the fetched values f of the loader wl to the store and runs the hooks,
+// strict is passed to apply
+func (c *S) loadValues(wl *loaderWatcher, f fetched, strict bool) error {
 	// we add the values to the store.
-	var updatedKeys, err = v.load(wl.values, c)
+	var cs, err = c.apply(map[*loaderWatcher]Values{wl: f.values}, wl, strict)
 	if err != nil {
 		return err
 	}
-
-	wl.values = v
+	c.committed(wl, f)
+
+	// run change hooks
+	c.mut.Lock()
+	var changeHooks = c.changeHooks
+	c.mut.Unlock()
+	if err := changeHooks.run(cs, wl); err != nil {
+		c.cfg.Logger.Get().Error("Error while running change hooks: " + err.Error())
+		return err
+	}
 
 	// run key hooks
-	if len(updatedKeys) != 0 && c.keyHooks != nil {
-		return c.keyHooks.runForKeys(updatedKeys, c)
+	if cs.Len() != 0 && c.keyHooks != nil {
+		return c.keyHooks.runForKeys(cs.Keys(), c)
 	}
 
-	// run the loader hooks
+	// run the loader hooks, they are run one at a time but without c.mut locked
+	// so that they can read the health, the history or the secrets of the store
 	if wl.loaderHooks != nil {
-		c.mut.Lock()
+		c.hooksMut.Lock()
 		if err := wl.loaderHooks.Run(c); err != nil {
 			c.cfg.Logger.Get().Error("Error while running loader hooks: " + err.Error())
-			c.mut.Unlock()
+			c.hooksMut.Unlock()
 			return err
 		}
-		c.mut.Unlock()
+		c.hooksMut.Unlock()
 	}
 
 	return nil
 }
 
-func (c *S) watchLoader(wl *loaderWatcher, panics int) {
+func (c *S) watchLoader(ctx context.Context, wl *loaderWatcher, panics int) {
+	defer c.wg.Done()
+
 	// if a panic occurs we log it
 	// then, if the current loader requires a to stop on failure, we stop everything,
 	// else, we restart the watcher.
@@ -187,15 +443,31 @@ func (c *S) watchLoader(wl *loaderWatcher, panics int) {
 				),
 			)
 			if wl.StopOnFailure() || panics >= c.cfg.MaxWatcherPanics {
-				c.stop()
+				c.setWatching(wl, false)
+				c.stop(fmt.Errorf("panic in loader %s: %v", wl.Name(), r))
 				return
 			}
-			go c.watchLoader(wl, panics+1)
+			c.mut.Lock()
+			wl.restarts++
+			c.mut.Unlock()
+			if !c.addWatcher() {
+				c.setWatching(wl, false)
+				return
+			}
+			go c.watchLoader(ctx, wl, panics+1)
+			return
 		}
+		c.setWatching(wl, false)
 	}()
 
 	for {
 		select {
+		case <-ctx.Done():
+			// the store is closed or the context is done, we stop the watcher
+			if err := wl.Close(); err != nil {
+				c.cfg.Logger.Get().Error(err.Error())
+			}
+			return
 		case <-wl.Done():
 			if err := wl.Err(); err != nil {
 				c.cfg.Logger.Get().Error(err.Error())
@@ -206,6 +478,11 @@ func (c *S) watchLoader(wl *loaderWatcher, panics int) {
 			// we got an event
 			// do a loaderLoadRetry
 			select {
+			case <-ctx.Done():
+				if err := wl.Close(); err != nil {
+					c.cfg.Logger.Get().Error(err.Error())
+				}
+				return
 			case <-wl.Done():
 				if err := wl.Err(); err != nil {
 					c.cfg.Logger.Get().Error(err.Error())
@@ -218,16 +495,18 @@ func (c *S) watchLoader(wl *loaderWatcher, panics int) {
 					t = prometheus.NewTimer(wl.metrics.configReloadDuration)
 				}
 
-				if err := c.loaderLoadRetry(wl, 0); err != nil {
+				// only the loader which changed is reloaded, its values are applied atomically
+				if err := c.loaderLoadRetry(ctx, wl, 0); err != nil {
 					// if metrics is enabled we record a load failure
 					if c.cfg.Metrics {
 						wl.metrics.configReloadFailure.Inc()
 						t.ObserveDuration()
 					}
-					if !wl.StopOnFailure() {
+					// if ctx is done the load has been aborted and the watcher is closed on the next iteration
+					if ctx.Err() != nil || !wl.StopOnFailure() {
 						continue
 					}
-					c.stop()
+					c.stop(err)
 					return
 				}
 
@@ -2,9 +2,11 @@ package klconsul
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/consul/api"
@@ -16,8 +18,8 @@ import (
 )
 
 var (
-	defaultTimeout               = 5 * time.Second
-	_              konfig.Loader = (*Loader)(nil)
+	defaultTimeout                      = 5 * time.Second
+	_              konfig.LoaderContext = (*Loader)(nil)
 )
 
 const (
@@ -135,8 +137,14 @@ func (l *Loader) Name() string { return l.cfg.Name }
 // it loads environment variables into the konfig.Store
 // based on config passed to the loader
 func (l *Loader) Load(s konfig.Values) error {
+	return l.LoadContext(context.Background(), s)
+}
+
+// LoadContext implements konfig.LoaderContext,
+// the context is passed to the consul queries
+func (l *Loader) LoadContext(ctx context.Context, s konfig.Values) error {
 	for _, k := range l.cfg.Keys {
-		kp, _, err := l.keyValue(k.Key)
+		kp, _, err := l.keyValue(ctx, k.Key)
 		if err != nil {
 			return err
 		}
@@ -183,10 +191,24 @@ func (l *Loader) StopOnFailure() bool {
 
 // keyValue is a quick helper to load KVPair from
 // the consul server
-func (l *Loader) keyValue(k string) (pair *api.KVPair, qm *api.QueryMeta, err error) {
-	return l.cfg.kvClient.Get(k, nil)
+func (l *Loader) keyValue(ctx context.Context, k string) (pair *api.KVPair, qm *api.QueryMeta, err error) {
+	var q *api.QueryOptions
+	// we attach the context only if it can be cancelled
+	if ctx.Done() != nil {
+		q = q.WithContext(ctx)
+	}
+	return l.cfg.kvClient.Get(k, q)
 }
 
 func defaultLogger() nlogger.Provider {
 	return nlogger.NewProvider(nlogger.New(os.Stdout, "CONSULLOADER | "))
 }
+
+// Source returns the consul keys, it implements konfig.Sourcer
+func (l *Loader) Source() string {
+	var keys = make([]string, len(l.cfg.Keys))
+	for i, k := range l.cfg.Keys {
+		keys[i] = k.Key
+	}
+	return strings.Join(keys, ",")
+}
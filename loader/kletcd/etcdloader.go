@@ -3,6 +3,7 @@ package kletcd
 import (
 	"bytes"
 	"context"
+	"strings"
 	"time"
 
 	"github.com/coreos/etcd/mvcc/mvccpb"
@@ -15,8 +16,8 @@ import (
 )
 
 var (
-	defaultTimeout               = 5 * time.Second
-	_              konfig.Loader = (*Loader)(nil)
+	defaultTimeout                      = 5 * time.Second
+	_              konfig.LoaderContext = (*Loader)(nil)
 )
 
 const (
@@ -115,9 +116,15 @@ func (l *Loader) Name() string { return l.cfg.Name }
 
 // Load loads the values from the keys defined by the config in the konfig.Store
 func (l *Loader) Load(s konfig.Values) error {
+	return l.LoadContext(context.Background(), s)
+}
+
+// LoadContext loads the values from the keys defined by the config in the konfig.Store.
+// The timeout for each key is derived from ctx.
+func (l *Loader) LoadContext(ctx context.Context, s konfig.Values) error {
 	for _, k := range l.cfg.Keys {
 
-		values, err := l.keyValue(k.Key)
+		values, err := l.keyValue(ctx, k.Key)
 		if err != nil {
 			return err
 		}
@@ -153,9 +160,9 @@ func (l *Loader) RetryDelay() time.Duration {
 	return l.cfg.RetryDelay
 }
 
-func (l *Loader) keyValue(k string) ([]*mvccpb.KeyValue, error) {
-	var ctx, cancel = l.cfg.Contexter.WithTimeout(
-		context.Background(),
+func (l *Loader) keyValue(ctx context.Context, k string) ([]*mvccpb.KeyValue, error) {
+	ctx, cancel := l.cfg.Contexter.WithTimeout(
+		ctx,
 		l.cfg.Timeout,
 	)
 	defer cancel()
@@ -172,3 +179,12 @@ func (l *Loader) keyValue(k string) ([]*mvccpb.KeyValue, error) {
 func (l *Loader) StopOnFailure() bool {
 	return l.cfg.StopOnFailure
 }
+
+// Source returns the etcd keys, it implements konfig.Sourcer
+func (l *Loader) Source() string {
+	var keys = make([]string, len(l.cfg.Keys))
+	for i, k := range l.cfg.Keys {
+		keys[i] = k.Key
+	}
+	return strings.Join(keys, ",")
+}
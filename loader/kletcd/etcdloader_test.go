@@ -410,11 +410,12 @@ func TestLoaderMethods(t *testing.T) {
 		RetryDelay:    10 * time.Second,
 		Client:        newClient(),
 		kvClient:      mockClient,
-		Keys:          []Key{{Key: "key1"}},
+		Keys:          []Key{{Key: "key1"}, {Key: "key2"}},
 	})
 
 	require.True(t, l.StopOnFailure())
 	require.Equal(t, "etcdloader", l.Name())
 	require.Equal(t, 1, l.MaxRetry())
 	require.Equal(t, 10*time.Second, l.RetryDelay())
+	require.Equal(t, "key1,key2", l.Source())
 }
@@ -3,6 +3,7 @@ package klfile
 import (
 	"errors"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/lalamove/konfig"
@@ -184,3 +185,12 @@ func (f *Loader) StopOnFailure() bool {
 func defaultLogger() nlogger.Provider {
 	return nlogger.NewProvider(nlogger.New(os.Stdout, "FILEWATCHER | "))
 }
+
+// Source returns the paths of the files, it implements konfig.Sourcer
+func (f *Loader) Source() string {
+	var paths = make([]string, len(f.cfg.Files))
+	for i, file := range f.cfg.Files {
+		paths[i] = file.Path
+	}
+	return strings.Join(paths, ",")
+}
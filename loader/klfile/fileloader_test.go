@@ -208,3 +208,8 @@ func TestNewFileLoader(t *testing.T) {
 		},
 	)
 }
+
+func TestSource(t *testing.T) {
+	var fl = NewFileLoader("config-files", kpjson.Parser, "foo.json", "bar.json")
+	require.Equal(t, "foo.json,bar.json", fl.Source())
+}
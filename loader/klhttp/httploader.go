@@ -1,9 +1,12 @@
 package klhttp
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/lalamove/konfig"
@@ -12,6 +15,8 @@ import (
 )
 
 var (
+	_ konfig.LoaderContext = (*Loader)(nil)
+
 	defaultRate = 10 * time.Second
 	// ErrNoSources is the error thrown when creating an Loader without sources
 	ErrNoSources = errors.New("No sources provided")
@@ -115,8 +120,13 @@ func (r *Loader) Name() string { return r.cfg.Name }
 
 // Load loads the config from sources and parses the response
 func (r *Loader) Load(s konfig.Values) error {
+	return r.LoadContext(context.Background(), s)
+}
+
+// LoadContext loads the config from sources with the given context and parses the response
+func (r *Loader) LoadContext(ctx context.Context, s konfig.Values) error {
 	for _, source := range r.cfg.Sources {
-		if b, err := source.Do(r.cfg.Client); err == nil {
+		if b, err := source.DoContext(ctx, r.cfg.Client); err == nil {
 			if err := source.Parser.Parse(b, s); err != nil {
 				return err
 			}
@@ -141,3 +151,27 @@ func (r *Loader) RetryDelay() time.Duration {
 func (r *Loader) StopOnFailure() bool {
 	return r.cfg.StopOnFailure
 }
+
+// Source returns the URLs of the sources without their credentials and query, it implements konfig.Sourcer
+func (r *Loader) Source() string {
+	var urls = make([]string, len(r.cfg.Sources))
+	for i, source := range r.cfg.Sources {
+		urls[i] = redactURL(source.URL)
+	}
+	return strings.Join(urls, ",")
+}
+
+// redactURL removes the user info, the query and the fragment of the URL u as they may hold credentials.
+// If u cannot be parsed, it returns an empty string.
+func redactURL(u string) string {
+	var pu, err = url.Parse(u)
+	if err != nil {
+		return ""
+	}
+	pu.User = nil
+	pu.RawQuery = ""
+	pu.ForceQuery = false
+	pu.Fragment = ""
+	pu.RawFragment = ""
+	return pu.String()
+}
@@ -1,6 +1,7 @@
 package klhttp
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,7 +9,13 @@ import (
 
 // Do makes an http request and sends the body to the parser
 func (s Source) Do(c Client) (io.Reader, error) {
-	var req, err = http.NewRequest(
+	return s.DoContext(context.Background(), c)
+}
+
+// DoContext makes an http request with the given context and sends the body to the parser
+func (s Source) DoContext(ctx context.Context, c Client) (io.Reader, error) {
+	var req, err = http.NewRequestWithContext(
+		ctx,
 		s.Method,
 		s.URL,
 		s.Body,
@@ -1,6 +1,7 @@
 package klvault
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,7 +18,7 @@ import (
 	"github.com/lalamove/nui/nstrings"
 )
 
-var _ konfig.Loader = (*Loader)(nil)
+var _ konfig.LoaderContext = (*Loader)(nil)
 
 var (
 	defaultTTL      = 45 * time.Minute
@@ -145,6 +146,12 @@ func (vl *Loader) RetryDelay() time.Duration {
 // It fetches a token from the auth provider and sets the token in the vault client.
 // Then it loads the secret and assigns it values to the konfig.Store.
 func (vl *Loader) Load(cs konfig.Values) error {
+	return vl.LoadContext(context.Background(), cs)
+}
+
+// LoadContext implements konfig.LoaderContext interface.
+// The vault client does not take a context, so ctx is checked before each secret is read.
+func (vl *Loader) LoadContext(ctx context.Context, cs konfig.Values) error {
 	if vl.cfg.Debug {
 		vl.cfg.Logger.Get().Debug(
 			"Loading vault config",
@@ -163,6 +170,10 @@ func (vl *Loader) Load(cs konfig.Values) error {
 
 	var leaseDuration = int(ttl / time.Second)
 	for _, secret := range vl.cfg.Secrets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		// we fetch our secret
 		var s *vault.Secret
 		var sData map[string]interface{}
@@ -245,3 +256,12 @@ func (vl *Loader) resetTTL(ttlFac int, tokenTTL, secretTTL time.Duration) {
 func defaultLogger() nlogger.Provider {
 	return nlogger.NewProvider(nlogger.New(os.Stdout, "VAULT CONFIG | "))
 }
+
+// Source returns the keys of the secrets, it implements konfig.Sourcer
+func (vl *Loader) Source() string {
+	var keys = make([]string, len(vl.cfg.Secrets))
+	for i, secret := range vl.cfg.Secrets {
+		keys[i] = secret.Key
+	}
+	return strings.Join(keys, ",")
+}
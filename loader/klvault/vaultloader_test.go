@@ -340,7 +340,7 @@ func TestMaxRetryRetryDelay(t *testing.T) {
 		vault.DefaultConfig(),
 	)
 	var vl = New(&Config{
-		Secrets:       []Secret{{Key: "/dummy/secretr/path"}},
+		Secrets:       []Secret{{Key: "/dummy/secretr/path"}, {Key: "/dummy/other/path"}},
 		AuthProvider:  aP,
 		Client:        c,
 		Renew:         true,
@@ -352,4 +352,5 @@ func TestMaxRetryRetryDelay(t *testing.T) {
 	require.True(t, vl.StopOnFailure())
 	require.Equal(t, 1, vl.MaxRetry())
 	require.Equal(t, 1*time.Second, vl.RetryDelay())
+	require.Equal(t, "/dummy/secretr/path,/dummy/other/path", vl.Source())
 }
@@ -5,6 +5,7 @@
 package konfig
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
 	time "time"
@@ -102,3 +103,110 @@ func (mr *MockLoaderMockRecorder) RetryDelay() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryDelay", reflect.TypeOf((*MockLoader)(nil).RetryDelay))
 }
+
+// MockLoaderContext is a mock of LoaderContext interface
+type MockLoaderContext struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoaderContextMockRecorder
+}
+
+// MockLoaderContextMockRecorder is the mock recorder for MockLoaderContext
+type MockLoaderContextMockRecorder struct {
+	mock *MockLoaderContext
+}
+
+// NewMockLoaderContext creates a new mock instance
+func NewMockLoaderContext(ctrl *gomock.Controller) *MockLoaderContext {
+	mock := &MockLoaderContext{ctrl: ctrl}
+	mock.recorder = &MockLoaderContextMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLoaderContext) EXPECT() *MockLoaderContextMockRecorder {
+	return m.recorder
+}
+
+// StopOnFailure mocks base method
+func (m *MockLoaderContext) StopOnFailure() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopOnFailure")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// StopOnFailure indicates an expected call of StopOnFailure
+func (mr *MockLoaderContextMockRecorder) StopOnFailure() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopOnFailure", reflect.TypeOf((*MockLoaderContext)(nil).StopOnFailure))
+}
+
+// Name mocks base method
+func (m *MockLoaderContext) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name
+func (mr *MockLoaderContextMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockLoaderContext)(nil).Name))
+}
+
+// Load mocks base method
+func (m *MockLoaderContext) Load(arg0 Values) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Load", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Load indicates an expected call of Load
+func (mr *MockLoaderContextMockRecorder) Load(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockLoaderContext)(nil).Load), arg0)
+}
+
+// MaxRetry mocks base method
+func (m *MockLoaderContext) MaxRetry() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxRetry")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxRetry indicates an expected call of MaxRetry
+func (mr *MockLoaderContextMockRecorder) MaxRetry() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxRetry", reflect.TypeOf((*MockLoaderContext)(nil).MaxRetry))
+}
+
+// RetryDelay mocks base method
+func (m *MockLoaderContext) RetryDelay() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryDelay")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// RetryDelay indicates an expected call of RetryDelay
+func (mr *MockLoaderContextMockRecorder) RetryDelay() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryDelay", reflect.TypeOf((*MockLoaderContext)(nil).RetryDelay))
+}
+
+// LoadContext mocks base method
+func (m *MockLoaderContext) LoadContext(arg0 context.Context, arg1 Values) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadContext", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoadContext indicates an expected call of LoadContext
+func (mr *MockLoaderContextMockRecorder) LoadContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadContext", reflect.TypeOf((*MockLoaderContext)(nil).LoadContext), arg0, arg1)
+}
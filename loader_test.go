@@ -1,7 +1,9 @@
 package konfig
 
 import (
+	"context"
 	"errors"
+	"sync"
 	"testing"
 	time "time"
 
@@ -190,7 +192,7 @@ func TestLoaderLoadRetry(t *testing.T) {
 				reset()
 				var c = instance()
 				c.cfg.NoExitOnError = true
-				var err = c.loaderLoadRetry(testCase.build(ctrl), 0)
+				var err = c.loaderLoadRetry(context.Background(), testCase.build(ctrl), 0)
 				if testCase.err {
 					require.NotNil(t, err, "err should not be nil")
 					return
@@ -248,10 +250,10 @@ func TestLoaderLoadRetryStrictKeys(t *testing.T) {
 		},
 	}
 
-	var err = c.loaderLoadRetry(wl, 0)
+	var err = c.loaderLoadRetry(context.Background(), wl, 0)
 	require.Nil(t, err, "err should be nil")
 
-	err = c.loaderLoadRetry(wl, 0)
+	err = c.loaderLoadRetry(context.Background(), wl, 0)
 	require.NotNil(t, err, "err should not be nil")
 }
 
@@ -345,22 +347,22 @@ func TestLoaderLoadRetryKeyHooks(t *testing.T) {
 		},
 	}
 
-	var err = c.loaderLoadRetry(wl, 0)
+	var err = c.loaderLoadRetry(context.Background(), wl, 0)
 	require.Nil(t, err, "err should be nil")
 
-	err = c.loaderLoadRetry(wl, 0)
+	err = c.loaderLoadRetry(context.Background(), wl, 0)
 	require.Nil(t, err, "err should not be nil")
 
-	err = c.loaderLoadRetry(wl, 0)
+	err = c.loaderLoadRetry(context.Background(), wl, 0)
 	require.Nil(t, err, "err should not be nil")
 
-	err = c.loaderLoadRetry(wl, 0)
+	err = c.loaderLoadRetry(context.Background(), wl, 0)
 	require.Nil(t, err, "err should not be nil")
 
-	err = c.loaderLoadRetry(wl, 0)
+	err = c.loaderLoadRetry(context.Background(), wl, 0)
 	require.Nil(t, err, "err should not be nil")
 
-	err = c.loaderLoadRetry(wl, 0)
+	err = c.loaderLoadRetry(context.Background(), wl, 0)
 	require.NotNil(t, err, "err should not be nil")
 
 	require.Equal(t, 4, ranTest, "ranTest should be 2")
@@ -522,3 +524,217 @@ func TestLoaderLoadWatch(t *testing.T) {
 		)
 	}
 }
+
+type ctxKey struct{}
+
+func TestLoaderLoadRetryContext(t *testing.T) {
+	t.Run(
+		"context cancelled aborts retries",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var c = New(DefaultConfig())
+			var mockW = NewMockWatcher(ctrl)
+			var mockL = NewMockLoader(ctrl)
+			var ctx, cancel = context.WithCancel(context.Background())
+
+			mockL.EXPECT().Load(Values{}).Do(func(Values) {
+				cancel()
+			}).Return(errors.New(""))
+
+			var wl = &loaderWatcher{
+				Watcher: mockW,
+				Loader:  mockL,
+			}
+
+			var err = c.loaderLoadRetry(ctx, wl, 0)
+			require.Equal(t, context.Canceled, err)
+		},
+	)
+
+	t.Run(
+		"loader context receives the context",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var c = New(DefaultConfig())
+			var mockL = NewMockLoaderContext(ctrl)
+			var ctx = context.WithValue(context.Background(), ctxKey{}, "bar")
+
+			mockL.EXPECT().LoadContext(gomock.Any(), Values{}).Do(func(ctx context.Context, v Values) {
+				v.Set("foo", ctx.Value(ctxKey{}))
+			}).Return(nil)
+
+			c.RegisterLoader(mockL)
+
+			require.Nil(t, c.LoadContext(ctx))
+			require.Equal(t, "bar", c.Get("foo"))
+		},
+	)
+
+	t.Run(
+		"plain loader with done context",
+		func(t *testing.T) {
+			var ctrl = gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var mockL = NewMockLoader(ctrl)
+			var ctx, cancel = context.WithCancel(context.Background())
+			cancel()
+
+			require.Equal(
+				t,
+				context.Canceled,
+				NewLoaderContext(mockL).LoadContext(ctx, Values{}),
+			)
+		},
+	)
+}
+
+type slowLoader struct {
+	valuesLoader
+	delay time.Duration
+	err   error
+}
+
+func (l *slowLoader) Load(v Values) error {
+	time.Sleep(l.delay)
+	if l.err != nil {
+		return l.err
+	}
+	return l.valuesLoader.Load(v)
+}
+
+// barrierLoader blocks until all loaders sharing its barrier have started loading
+type barrierLoader struct {
+	slowLoader
+	barrier *sync.WaitGroup
+}
+
+func (l *barrierLoader) Load(v Values) error {
+	l.barrier.Done()
+
+	var done = make(chan struct{})
+	go func() {
+		l.barrier.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		return errors.New("loaders are not fetched concurrently")
+	}
+	return l.slowLoader.Load(v)
+}
+
+func TestLoadParallel(t *testing.T) {
+	t.Run(
+		"loaders are fetched concurrently and loaded in order",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.ParallelLoad = true
+			var c = New(cfg)
+
+			var order []string
+			var hook = func(name string) func(Store) error {
+				return func(Store) error {
+					order = append(order, name)
+					return nil
+				}
+			}
+			// each loader waits for all of them to start, the loads only succeed if they run concurrently,
+			// the second loader finishes first but the loaders are loaded in order
+			var barrier = &sync.WaitGroup{}
+			barrier.Add(3)
+			c.RegisterLoader(
+				&barrierLoader{
+					slowLoader: slowLoader{valuesLoader: valuesLoader{values: Values{"foo": "a"}}, delay: 50 * time.Millisecond},
+					barrier:    barrier,
+				},
+				hook("a"),
+			)
+			c.RegisterLoader(
+				&barrierLoader{
+					slowLoader: slowLoader{valuesLoader: valuesLoader{values: Values{"foo": "b"}}},
+					barrier:    barrier,
+				},
+				hook("b"),
+			)
+			c.RegisterLoader(
+				&barrierLoader{
+					slowLoader: slowLoader{valuesLoader: valuesLoader{values: Values{"bar": "c"}}, delay: 50 * time.Millisecond},
+					barrier:    barrier,
+				},
+				hook("c"),
+			)
+
+			require.Nil(t, c.Load())
+			require.Equal(t, []string{"a", "b", "c"}, order)
+			require.Equal(t, "b", c.MustString("foo"))
+			require.Equal(t, "c", c.MustString("bar"))
+		},
+	)
+
+	t.Run(
+		"all failures are returned",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.ParallelLoad = true
+			var c = New(cfg)
+
+			var err1, err2 = errors.New("err1"), errors.New("err2")
+			c.RegisterLoader(&slowLoader{err: err1})
+			c.RegisterLoader(&slowLoader{valuesLoader: valuesLoader{values: Values{"foo": "bar"}}})
+			c.RegisterLoader(&slowLoader{err: err2})
+
+			var err = c.Load()
+			require.NotNil(t, err)
+			require.True(t, errors.Is(err, err1))
+			require.True(t, errors.Is(err, err2))
+
+			// the values of the loaders which succeeded are loaded
+			require.Equal(t, "bar", c.MustString("foo"))
+			require.False(t, c.Health().Ready)
+		},
+	)
+
+	t.Run(
+		"stop on failure",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.ParallelLoad = true
+			var stopErr error
+			cfg.OnFatal = func(err error) {
+				stopErr = err
+			}
+			var c = New(cfg)
+
+			var err = errors.New("err")
+			c.RegisterLoader(&slowLoader{
+				valuesLoader: valuesLoader{DummyLoader: DummyLoader{stopOnFailure: true}},
+				err:          err,
+			})
+			c.RegisterLoader(&slowLoader{valuesLoader: valuesLoader{values: Values{"foo": "bar"}}})
+
+			require.NotNil(t, c.Load())
+			require.True(t, errors.Is(stopErr, err))
+			require.False(t, c.Exists("foo"))
+		},
+	)
+}
+
+func TestLoadLoader(t *testing.T) {
+	var c = New(DefaultConfig())
+	var l = &valuesLoader{values: Values{"foo": "bar"}}
+	c.RegisterLoader(l)
+	c.RegisterLoader(&failingLoader{fail: true})
+
+	require.Equal(t, ErrLoaderNotFound, c.LoadLoader("unknown"))
+
+	// the first loader with the name is loaded
+	require.Nil(t, c.LoadLoader("dummy"))
+	require.Equal(t, "bar", c.MustString("foo"))
+}
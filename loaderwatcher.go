@@ -1,5 +1,11 @@
 package konfig
 
+import (
+	"context"
+	"sync"
+	"time"
+)
+
 // LoaderWatcher is an interface that implements both loader and watcher
 type LoaderWatcher interface {
 	Loader
@@ -10,10 +16,25 @@ type loaderWatcher struct {
 	Loader
 	Watcher
 	values      Values
+	rawKeys     map[string]string
+	loadedAt    time.Time
+	priority    int
+	retryPolicy RetryPolicy
+	stale       error
+	staleAt     time.Time
+	lastLoadAt  time.Time
+	lastErr     error
+	lastErrAt   time.Time
+	failures    int
+	restarts    int
+	watching    bool
 	name        string
 	s           *S
 	metrics     *loaderMetrics
 	loaderHooks LoaderHooks
+	deprecated  map[string]struct{}
+	closeOnce   sync.Once
+	closeErr    error
 }
 
 // NewLoaderWatcher creates a new LoaderWatcher from a Loader and a Watcher
@@ -38,3 +59,24 @@ func (c *S) newLoaderWatcher(l Loader, w Watcher, loaderHooks LoaderHooks) *load
 
 	return lw
 }
+
+// LoadContext calls LoadContext on the underlying Loader if it implements LoaderContext, else it calls Load.
+func (lw *loaderWatcher) LoadContext(ctx context.Context, v Values) error {
+	return NewLoaderContext(lw.Loader).LoadContext(ctx, v)
+}
+
+// Close closes the underlying Watcher once, subsequent calls return the same error.
+func (lw *loaderWatcher) Close() error {
+	lw.closeOnce.Do(func() {
+		lw.closeErr = lw.Watcher.Close()
+	})
+	return lw.closeErr
+}
+
+// Source returns the source of the underlying Loader if it implements Sourcer
+func (lw *loaderWatcher) Source() string {
+	if s, ok := lw.Loader.(Sourcer); ok {
+		return s.Source()
+	}
+	return ""
+}
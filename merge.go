@@ -0,0 +1,396 @@
+package konfig
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// MergeStrategy is the strategy used to merge the values of a key set by multiple loaders
+type MergeStrategy int
+
+const (
+	// MergeOverride replaces the value with the value of the loader with the highest precedence. It is the default strategy.
+	MergeOverride MergeStrategy = iota
+	// MergeDeep merges maps recursively, the loader with the highest precedence wins on conflicting keys.
+	MergeDeep
+	// MergeAppend appends slices in order of precedence.
+	MergeAppend
+)
+
+// SetMergeStrategy sets the merge strategy for the key prefix p in the global store
+func SetMergeStrategy(p string, m MergeStrategy) Store {
+	return instance().SetMergeStrategy(p, m)
+}
+
+// SetMergeStrategy sets the merge strategy for the key prefix p.
+// The strategy of the longest matching prefix applies to a key.
+func (c *S) SetMergeStrategy(p string, m MergeStrategy) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.strategies == nil {
+		c.strategies = make(map[string]MergeStrategy)
+	}
+	c.strategies[p] = m
+	return c
+}
+
+// WithPriority sets the priority of the loader. When multiple loaders set the same key,
+// the value of the loader with the highest priority wins. Loaders with the same priority
+// take precedence by order of registration, the last registered wins.
+// The new priority applies from the next load of a loader.
+func (cl *ConfigLoader) WithPriority(p int) *ConfigLoader {
+	cl.s.mut.Lock()
+	defer cl.s.mut.Unlock()
+
+	cl.loaderWatcher.priority = p
+	return cl
+}
+
+// hasKeyPrefix checks whether the key k is p or is under the path p
+func hasKeyPrefix(k, p string) bool {
+	if p == "" || k == p {
+		return true
+	}
+	return strings.HasPrefix(k, strings.TrimSuffix(p, KeySep)+KeySep)
+}
+
+// strategy returns the merge strategy of the longest prefix matching the key k
+func (c *S) strategy(k string) MergeStrategy {
+	var strategy = MergeOverride
+	var l = -1
+	for p, m := range c.strategies {
+		if len(p) > l && hasKeyPrefix(k, p) {
+			strategy = m
+			l = len(p)
+		}
+	}
+	return strategy
+}
+
+// addLayer adds the loader to the layers merged in the store if it is not already there
+func (c *S) addLayer(wl *loaderWatcher) {
+	for _, l := range c.layers {
+		if l == wl {
+			return
+		}
+	}
+	c.layers = append(c.layers, wl)
+}
+
+// sortedLayers returns the loaders merged in the store by order of precedence, the last one wins.
+// Loaders are sorted by priority, then by order of registration.
+func (c *S) sortedLayers() []*loaderWatcher {
+	var idx = make(map[*loaderWatcher]int, len(c.layers))
+	for i, wl := range c.WatcherLoaders {
+		idx[wl] = i
+	}
+	for i, wl := range c.layers {
+		// loaders which are not registered come after the registered ones
+		if _, ok := idx[wl]; !ok {
+			idx[wl] = len(c.WatcherLoaders) + i
+		}
+	}
+
+	var layers = make([]*loaderWatcher, len(c.layers))
+	copy(layers, c.layers)
+	sort.SliceStable(layers, func(i, j int) bool {
+		if layers[i].priority != layers[j].priority {
+			return layers[i].priority < layers[j].priority
+		}
+		return idx[layers[i]] < idx[layers[j]]
+	})
+
+	return layers
+}
+
+// releaseSets removes the keys loaded by the staged loaders from the values set with Set,
+// as a load replaces the values set on the keys the loader sets or used to set.
+// It returns the previous values set with Set.
+func (c *S) releaseSets(staged map[*loaderWatcher]Values) Values {
+	var prev = c.sets
+	var al = c.aliases.Load().(aliases)
+	var sets Values
+	for wl, x := range staged {
+		for _, values := range []Values{x, wl.values} {
+			for k := range values {
+				k = al.key(k)
+				if _, ok := prev[k]; !ok {
+					continue
+				}
+				// the set layer is copied as it is referenced by snapshots
+				if sets == nil {
+					sets = make(Values, len(prev))
+					for kk, vv := range prev {
+						sets[kk] = vv
+					}
+				}
+				delete(sets, k)
+			}
+		}
+	}
+	if sets != nil {
+		c.sets = sets
+	}
+	return prev
+}
+
+// merge computes the store values and their origins from the values of all loaders, the values set with Set and the overrides.
+// staged values replace the current values of the given loaders.
+func (c *S) merge(staged map[*loaderWatcher]Values, t time.Time) (s, origins) {
+	var nm = make(s)
+	var no = make(origins)
+
+	var al = c.aliases.Load().(aliases)
+	var apply = func(x Values, or origin, warn bool) {
+		for k, v := range x {
+			// values set under an old key are stored under the new key,
+			// if both keys are set the new key wins
+			if nk := al.key(k); nk != k {
+				if _, ok := x[nk]; ok {
+					continue
+				}
+				if warn {
+					c.deprecated(or.lw, k, nk)
+				}
+				k = nk
+			}
+			if ov, ok := nm[k]; ok {
+				v = mergeValues(c.strategy(k), ov, v)
+			}
+			nm[k] = v
+			no[k] = append([]origin{or}, no[k]...)
+		}
+	}
+
+	for _, wl := range c.sortedLayers() {
+		// deprecation warnings are reported for the values being loaded only
+		if x, ok := staged[wl]; ok {
+			apply(x, origin{lw: wl, loadedAt: t}, true)
+			continue
+		}
+		apply(wl.values, origin{lw: wl, loadedAt: wl.loadedAt}, false)
+	}
+
+	// values set with Set win over loaders until a loader loads the key again,
+	// the same goes for keys deleted with Delete
+	for k, v := range c.sets {
+		// values set under an old key are stored under the new key, if both keys are set the new key wins
+		if nk := al.key(k); nk != k {
+			if _, ok := c.sets[nk]; ok {
+				continue
+			}
+			k = nk
+		}
+		if _, ok := v.(deletedKey); ok {
+			delete(nm, k)
+			delete(no, k)
+			continue
+		}
+		nm[k] = v
+		no[k] = append([]origin{{loadedAt: c.setAt}}, no[k]...)
+	}
+
+	// overrides always win over loaders
+	for k, o := range c.overrides {
+		nm[k] = o.v
+		no[k] = append([]origin{{loadedAt: o.at, override: true}}, no[k]...)
+	}
+
+	rebuildArrays(nm)
+
+	return nm, no
+}
+
+// rebuildArrays rebuilds the arrays of m whose indexed keys (servers.0.host) hold values which are not
+// the values of the array, so that an indexed key set by another loader is reflected in the whole array.
+// Elements with an index greater than the length of the array are appended in order of index.
+// Nested arrays are rebuilt first.
+func rebuildArrays(m s) {
+	// we collect the indexed keys of each array
+	var arrays = make(map[string][]string)
+	for k, v := range m {
+		if v == nil {
+			continue
+		}
+		if kind := reflect.TypeOf(v).Kind(); kind == reflect.Slice || kind == reflect.Array {
+			arrays[k] = nil
+		}
+	}
+	if len(arrays) == 0 {
+		return
+	}
+	for k := range m {
+		var ks = strings.Split(k, KeySep)
+		for i := 1; i < len(ks); i++ {
+			var p = strings.Join(ks[:i], KeySep)
+			if _, ok := arrays[p]; ok {
+				if _, ok := index(ks[i]); ok {
+					arrays[p] = append(arrays[p], k)
+				}
+			}
+		}
+	}
+
+	var keys = make([]string, 0, len(arrays))
+	for k, sub := range arrays {
+		if len(sub) != 0 {
+			keys = append(keys, k)
+		}
+	}
+	// the deepest arrays are rebuilt first, so that they are rebuilt when their parent is
+	sort.Slice(keys, func(i, j int) bool {
+		var ni, nj = strings.Count(keys[i], KeySep), strings.Count(keys[j], KeySep)
+		if ni != nj {
+			return ni > nj
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, k := range keys {
+		var sub = make(map[string]interface{}, len(arrays[k]))
+		for _, kk := range arrays[k] {
+			sub[kk[len(k)+len(KeySep):]] = m[kk]
+		}
+		if v, ok := rebuildArray(m[k], sub); ok {
+			m[k] = v
+		}
+	}
+}
+
+// rebuildArray returns the array a with the values of its indexed keys sub (0.host) and whether they changed it
+func rebuildArray(a interface{}, sub map[string]interface{}) (interface{}, bool) {
+	// the values of the indexed keys are unflattened as unflatten does, a key holding a value hides its subkeys
+	var tree = unflatten(sub)
+
+	var changed bool
+	for k, v := range flatLeaves(sub) {
+		if ov, ok := lookupPath(a, strings.Split(k, KeySep)); !ok || !reflect.DeepEqual(ov, v) {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return a, false
+	}
+
+	var av = reflect.ValueOf(a)
+	var res = make([]interface{}, av.Len(), av.Len()+len(tree))
+	for i := range res {
+		res[i] = av.Index(i).Interface()
+	}
+
+	var idx = make([]int, 0, len(tree))
+	for k := range tree {
+		var i, _ = index(k)
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+
+	for _, i := range idx {
+		var v = tree[strconv.Itoa(i)]
+		if i >= len(res) {
+			res = append(res, v)
+			continue
+		}
+		if _, ok := v.(map[string]interface{}); ok {
+			v = mergeValues(MergeDeep, res[i], v)
+		}
+		res[i] = v
+	}
+	return res, true
+}
+
+// flatLeaves returns the keys of m which are not subkeys of another key of m, with their values
+func flatLeaves(m map[string]interface{}) map[string]interface{} {
+	var leaves = make(map[string]interface{}, len(m))
+keys:
+	for k, v := range m {
+		var ks = strings.Split(k, KeySep)
+		for i := 1; i < len(ks); i++ {
+			if _, ok := m[strings.Join(ks[:i], KeySep)]; ok {
+				continue keys
+			}
+		}
+		leaves[k] = v
+	}
+	return leaves
+}
+
+// lookupPath returns the value at the path ks in v following maps and arrays, and whether it exists
+func lookupPath(v interface{}, ks []string) (interface{}, bool) {
+	for _, k := range ks {
+		var ok bool
+		switch vt := v.(type) {
+		case map[string]interface{}:
+			v, ok = vt[k]
+		case map[interface{}]interface{}:
+			v, ok = vt[k]
+		default:
+			var rv = reflect.ValueOf(v)
+			if v == nil || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+				return nil, false
+			}
+			var i int
+			if i, ok = index(k); ok && i < rv.Len() {
+				v = rv.Index(i).Interface()
+			} else {
+				ok = false
+			}
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// mergeValues merges the value v over the value ov with the given strategy
+func mergeValues(strategy MergeStrategy, ov, v interface{}) interface{} {
+	switch strategy {
+	case MergeDeep:
+		var om, err = cast.ToStringMapE(ov)
+		if err != nil {
+			return v
+		}
+		nm, err := cast.ToStringMapE(v)
+		if err != nil {
+			return v
+		}
+		var res = make(map[string]interface{}, len(om)+len(nm))
+		for k, vv := range om {
+			res[k] = vv
+		}
+		for k, vv := range nm {
+			if ovv, ok := res[k]; ok {
+				vv = mergeValues(strategy, ovv, vv)
+			}
+			res[k] = vv
+		}
+		return res
+	case MergeAppend:
+		var os, ns = reflect.ValueOf(ov), reflect.ValueOf(v)
+		if os.Kind() != reflect.Slice || ns.Kind() != reflect.Slice {
+			return v
+		}
+		if os.Type() == ns.Type() {
+			var res = reflect.MakeSlice(os.Type(), 0, os.Len()+ns.Len())
+			res = reflect.AppendSlice(res, os)
+			return reflect.AppendSlice(res, ns).Interface()
+		}
+		var res = make([]interface{}, 0, os.Len()+ns.Len())
+		for i := 0; i < os.Len(); i++ {
+			res = append(res, os.Index(i).Interface())
+		}
+		for i := 0; i < ns.Len(); i++ {
+			res = append(res, ns.Index(i).Interface())
+		}
+		return res
+	}
+	return v
+}
@@ -0,0 +1,182 @@
+package konfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type valuesLoader struct {
+	DummyLoader
+	values Values
+}
+
+func (l *valuesLoader) Load(v Values) error {
+	for k, vv := range l.values {
+		v.Set(k, vv)
+	}
+	return nil
+}
+
+func TestPriority(t *testing.T) {
+	t.Run(
+		"highest priority wins regardless of load order",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var env = &valuesLoader{values: Values{"db.host": "env"}}
+			var consul = &valuesLoader{values: Values{"db.host": "consul", "db.port": 5432}}
+
+			c.RegisterLoader(env).WithPriority(10)
+			var consulLoader = c.RegisterLoader(consul)
+
+			require.Nil(t, c.Load())
+			require.Equal(t, "env", c.MustString("db.host"))
+			require.Equal(t, 5432, c.MustInt("db.port"))
+
+			// a reload of the consul loader does not override the env value
+			consul.values = Values{"db.host": "consul2"}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), consulLoader.loaderWatcher, 0))
+			require.Equal(t, "env", c.MustString("db.host"))
+			require.False(t, c.Exists("db.port"))
+
+			var or, ok = c.Origin("db.host")
+			require.True(t, ok)
+			require.Len(t, or.Shadowed, 1)
+		},
+	)
+
+	t.Run(
+		"same priority last registered wins",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var first = c.RegisterLoader(&valuesLoader{values: Values{"foo": "first"}})
+			c.RegisterLoader(&valuesLoader{values: Values{"foo": "second"}})
+
+			require.Nil(t, c.Load())
+			require.Equal(t, "second", c.MustString("foo"))
+
+			require.Nil(t, c.loaderLoadRetry(context.Background(), first.loaderWatcher, 0))
+			require.Equal(t, "second", c.MustString("foo"))
+		},
+	)
+
+	t.Run(
+		"set values are kept on reload",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.Set("foo", "default")
+			c.Set("bar", "default")
+			c.RegisterLoader(&valuesLoader{values: Values{"foo": "loaded"}})
+
+			require.Nil(t, c.Load())
+			require.Equal(t, "loaded", c.MustString("foo"))
+			require.Equal(t, "default", c.MustString("bar"))
+		},
+	)
+
+	t.Run(
+		"set values win until the key is loaded again",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var first = c.RegisterLoader(&valuesLoader{values: Values{"foo": "first"}})
+			var second = c.RegisterLoader(&valuesLoader{values: Values{"bar": "second"}})
+
+			require.Nil(t, c.Load())
+			c.Set("foo", "set")
+
+			// a reload of another loader keeps the set value
+			require.Nil(t, c.loaderLoadRetry(context.Background(), second.loaderWatcher, 0))
+			require.Equal(t, "set", c.MustString("foo"))
+
+			// a reload of the loader setting the key replaces it
+			require.Nil(t, c.loaderLoadRetry(context.Background(), first.loaderWatcher, 0))
+			require.Equal(t, "first", c.MustString("foo"))
+		},
+	)
+}
+
+func TestMergeStrategy(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		strategy MergeStrategy
+		prefix   string
+		first    interface{}
+		second   interface{}
+		expected interface{}
+	}{
+		{
+			name:     "override",
+			strategy: MergeOverride,
+			prefix:   "foo",
+			first:    map[string]interface{}{"a": 1},
+			second:   map[string]interface{}{"b": 2},
+			expected: map[string]interface{}{"b": 2},
+		},
+		{
+			name:     "deep",
+			strategy: MergeDeep,
+			prefix:   "foo",
+			first:    map[string]interface{}{"a": 1, "c": map[string]interface{}{"d": 1, "e": 1}},
+			second:   map[string]interface{}{"b": 2, "c": map[string]interface{}{"d": 2}},
+			expected: map[string]interface{}{
+				"a": 1,
+				"b": 2,
+				"c": map[string]interface{}{"d": 2, "e": 1},
+			},
+		},
+		{
+			name:     "append",
+			strategy: MergeAppend,
+			prefix:   "foo",
+			first:    []string{"a"},
+			second:   []string{"b", "c"},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "append different types",
+			strategy: MergeAppend,
+			prefix:   "",
+			first:    []interface{}{"a"},
+			second:   []string{"b"},
+			expected: []interface{}{"a", "b"},
+		},
+		{
+			name:     "prefix does not match",
+			strategy: MergeAppend,
+			prefix:   "fo",
+			first:    []string{"a"},
+			second:   []string{"b"},
+			expected: []string{"b"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(
+			testCase.name,
+			func(t *testing.T) {
+				var c = New(DefaultConfig())
+				c.SetMergeStrategy(testCase.prefix, testCase.strategy)
+				c.RegisterLoader(&valuesLoader{values: Values{"foo": testCase.first}})
+				c.RegisterLoader(&valuesLoader{values: Values{"foo": testCase.second}})
+
+				require.Nil(t, c.Load())
+				require.Equal(t, testCase.expected, c.Get("foo"))
+			},
+		)
+	}
+
+	t.Run(
+		"longest prefix wins",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.SetMergeStrategy("foo", MergeAppend)
+			c.SetMergeStrategy("foo.bar", MergeOverride)
+
+			require.Equal(t, MergeAppend, c.strategy("foo.baz"))
+			require.Equal(t, MergeOverride, c.strategy("foo.bar"))
+			require.Equal(t, MergeOverride, c.strategy("foo.bar.baz"))
+			require.Equal(t, MergeOverride, c.strategy("foobar"))
+		},
+	)
+}
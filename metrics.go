@@ -7,11 +7,16 @@ var (
 	MetricsConfigReload = "konfig_loader_reload"
 	// MetricsConfigReloadDuration is the label for the prometheus summary vector for loader reload duration
 	MetricsConfigReloadDuration = "konfig_loader_reload_duration"
+	// MetricsDeprecatedKey is the label for the prometheus counter for deprecated keys set by loaders
+	MetricsDeprecatedKey = "konfig_deprecated_key"
+	// MetricsStaleLoader is the label for the prometheus gauge telling whether a loader serves stale values
+	MetricsStaleLoader = "konfig_loader_stale"
 )
 
 const (
 	metricsSuccessLabel = "success"
 	metricsFailureLabel = "failure"
+	metricsRetryLabel   = "retry"
 )
 
 // LoaderMetrics is the structure holding the promtheus metrics objects
@@ -65,6 +70,20 @@ func (c *S) initMetrics() {
 			},
 			[]string{"store", "loader"},
 		),
+		MetricsDeprecatedKey: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: MetricsDeprecatedKey,
+				Help: "Number of deprecated config keys set by loaders",
+			},
+			[]string{"store", "loader", "key"},
+		),
+		MetricsStaleLoader: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: MetricsStaleLoader,
+				Help: "Whether a config loader serves stale values from its fallback",
+			},
+			[]string{"store", "loader"},
+		),
 	}
 }
 
@@ -0,0 +1,114 @@
+package konfig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var keyReplacer = strings.NewReplacer("_", KeySep, "-", KeySep)
+
+// NormalizeKey is a key normalizer lower casing keys and replacing '_' and '-' with '.',
+// so that DB_HOST, db-host and db.host are the same key.
+func NormalizeKey(k string) string {
+	return keyReplacer.Replace(strings.ToLower(k))
+}
+
+// normalize returns the key k normalized with the key normalizer of the store
+func (c *S) normalize(k string) string {
+	if c.cfg.KeyNormalizer == nil {
+		return k
+	}
+	return c.cfg.KeyNormalizer(k)
+}
+
+// normalizeValues returns the values x of the loader wl with normalized keys, and the original keys of the keys
+// changed by the normalization. If several keys of x are the same once normalized, the key already normalized wins,
+// else the first one in lexical order, and the collision is reported.
+func (c *S) normalizeValues(wl *loaderWatcher, x Values) (Values, map[string]string) {
+	if c.cfg.KeyNormalizer == nil {
+		return x, nil
+	}
+
+	var keys = make([]string, 0, len(x))
+	for k := range x {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var nx = make(Values, len(x))
+	var raw = make(map[string]string)
+	for _, k := range keys {
+		var nk = c.normalize(k)
+		if _, ok := nx[nk]; ok {
+			var rk, renamed = raw[nk]
+			if !renamed {
+				rk = nk
+			}
+			c.collision(wl, rk, wl, k, nk)
+			if k != nk {
+				continue
+			}
+			delete(raw, nk)
+		} else if k != nk {
+			raw[nk] = k
+		}
+		nx[nk] = x[k]
+	}
+
+	return nx, raw
+}
+
+// reportCollisions reports the keys of the values x of the loader wl which are the same as keys
+// of other loaders once normalized. raw holds the original keys of the keys of x.
+func (c *S) reportCollisions(wl *loaderWatcher, x Values, raw map[string]string) {
+	for _, lw := range c.layers {
+		if lw == wl {
+			continue
+		}
+		for k := range lw.values {
+			if _, ok := x[k]; !ok {
+				continue
+			}
+			var rk, ork = k, k
+			if v, ok := raw[k]; ok {
+				rk = v
+			}
+			if v, ok := lw.rawKeys[k]; ok {
+				ork = v
+			}
+			if rk != ork {
+				c.collision(lw, ork, wl, rk, k)
+			}
+		}
+	}
+}
+
+// keyCollision is a pair of keys of loaders which are the same key once normalized
+type keyCollision struct {
+	lw1, lw2 *loaderWatcher
+	k1, k2   string
+}
+
+// collision reports that the key k1 of the loader lw1 and the key k2 of the loader lw2 are the key k once normalized,
+// each pair of keys is reported once so that reloads don't repeat it
+func (c *S) collision(lw1 *loaderWatcher, k1 string, lw2 *loaderWatcher, k2, k string) {
+	var kc = keyCollision{lw1: lw1, lw2: lw2, k1: k1, k2: k2}
+	if _, ok := c.collisions[kc]; ok {
+		return
+	}
+	if _, ok := c.collisions[keyCollision{lw1: lw2, lw2: lw1, k1: k2, k2: k1}]; ok {
+		return
+	}
+	if c.collisions == nil {
+		c.collisions = make(map[keyCollision]struct{})
+	}
+	c.collisions[kc] = struct{}{}
+
+	c.cfg.Logger.Get().Warn(
+		fmt.Sprintf(
+			"config key \"%s\" of loader %s and config key \"%s\" of loader %s are the same key \"%s\" once normalized",
+			k1, lw1.Name(), k2, lw2.Name(), k,
+		),
+	)
+}
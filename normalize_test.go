@@ -0,0 +1,99 @@
+package konfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lalamove/nui/nlogger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeKey(t *testing.T) {
+	require.Equal(t, "db.host", NormalizeKey("DB_HOST"))
+	require.Equal(t, "db.host", NormalizeKey("db-host"))
+	require.Equal(t, "db.host", NormalizeKey("db.host"))
+}
+
+func TestKeyNormalizer(t *testing.T) {
+	t.Run(
+		"loaders and reads",
+		func(t *testing.T) {
+			var buf bytes.Buffer
+			var cfg = DefaultConfig()
+			cfg.Logger = nlogger.NewProvider(nlogger.New(&buf, ""))
+			cfg.KeyNormalizer = NormalizeKey
+			var c = New(cfg)
+
+			type DB struct {
+				Host string `konfig:"DB_HOST"`
+			}
+			c.Bind(DB{})
+
+			c.RegisterLoader(&valuesLoader{values: Values{"db.host": "file", "db.port": 5432}})
+			c.RegisterLoader(&valuesLoader{values: Values{"DB_HOST": "env"}})
+			require.Nil(t, c.Load())
+
+			require.Equal(t, []string{"db.host", "db.port"}, c.Keys(""))
+			require.Equal(t, "env", c.String("DB_HOST"))
+			require.Equal(t, "env", c.String("db-host"))
+			require.Equal(t, "env", c.View().String("Db.Host"))
+			require.Equal(t, 5432, c.Sub("DB").Int("port"))
+			require.Equal(t, DB{Host: "env"}, c.Value())
+			require.Contains(
+				t,
+				buf.String(),
+				`config key "db.host" of loader dummy and config key "DB_HOST" of loader dummy are the same key "db.host" once normalized`,
+			)
+
+			c.Set("DB-HOST", "set")
+			require.Equal(t, "set", c.String("db.host"))
+			require.Equal(t, DB{Host: "set"}, c.Value())
+
+			c.Secret("DB_PASSWORD")
+			require.True(t, c.IsSecret("db.password"))
+			require.False(t, c.IsSecret("db.host"))
+		},
+	)
+
+	t.Run(
+		"collision in a loader",
+		func(t *testing.T) {
+			var buf bytes.Buffer
+			var cfg = DefaultConfig()
+			cfg.Logger = nlogger.NewProvider(nlogger.New(&buf, ""))
+			cfg.KeyNormalizer = NormalizeKey
+			var c = New(cfg)
+
+			c.RegisterLoader(&valuesLoader{values: Values{"DB_HOST": "a", "db.host": "b", "db-host": "c"}})
+			require.Nil(t, c.Load())
+
+			// the key already normalized wins
+			require.Equal(t, "b", c.String("db.host"))
+			require.Contains(t, buf.String(), `config key "DB_HOST" of loader dummy and config key "db-host" of loader dummy`)
+			require.Contains(t, buf.String(), `config key "DB_HOST" of loader dummy and config key "db.host" of loader dummy`)
+
+			// collisions are reported once
+			require.Nil(t, c.Reload())
+			require.Equal(t, 1, strings.Count(buf.String(), `config key "DB_HOST" of loader dummy and config key "db.host" of loader dummy`))
+		},
+	)
+
+	t.Run(
+		"map keys under a bound prefix",
+		func(t *testing.T) {
+			type Config struct {
+				Labels map[string]string `konfig:"labels"`
+			}
+
+			var cfg = DefaultConfig()
+			cfg.KeyNormalizer = NormalizeKey
+			var c = New(cfg)
+			c.Bind(Config{})
+
+			c.RegisterLoader(&valuesLoader{values: Values{"labels.Team": "payments"}})
+			require.Nil(t, c.Load())
+			require.Equal(t, Config{Labels: map[string]string{"team": "payments"}}, c.Value())
+		},
+	)
+}
@@ -0,0 +1,110 @@
+package konfig
+
+import (
+	"sort"
+	"time"
+)
+
+// OriginSet is the loader name recorded in the Origin of keys set with Set
+const OriginSet = "set"
+
+// OriginOverride is the loader name recorded in the Origin of keys set with Override
+const OriginOverride = "override"
+
+// OriginDelete is the loader name set in the ChangeSet of a Delete
+const OriginDelete = "delete"
+
+// Sourcer is the interface a Loader can implement to describe where it loads values from,
+// for example a file path, a consul key or a URL.
+// The source describes the whole loader, not a single key: a loader reading several files or keys
+// returns all of them, the built in loaders join them with a comma.
+type Sourcer interface {
+	// Source returns the source of the loader
+	Source() string
+}
+
+// Origin describes where the value of a key comes from
+type Origin struct {
+	// Key is the config key
+	Key string
+	// Loader is the name of the loader which set the key
+	Loader string
+	// Source is the source of the loader if it implements Sourcer,
+	// it is the same for all keys set by the loader
+	Source string
+	// LoadedAt is the time at which the key was loaded
+	LoadedAt time.Time
+	// Shadowed is the list of origins which also set the key but were overridden
+	Shadowed []Origin
+}
+
+// origin is an entry in the origins of a key, lw is nil when the key was set with Set or Override
+type origin struct {
+	lw       *loaderWatcher
+	loadedAt time.Time
+	override bool
+}
+
+func (o origin) export(k string) Origin {
+	var or = Origin{
+		Key:      k,
+		Loader:   OriginSet,
+		LoadedAt: o.loadedAt,
+	}
+	if o.lw != nil {
+		or.Loader = o.lw.Name()
+		or.Source = o.lw.Source()
+	} else if o.override {
+		or.Loader = OriginOverride
+	}
+	return or
+}
+
+// origins holds for each key the list of origins setting it, the first one is the effective one
+type origins map[string][]origin
+
+func (o origins) origin(k string) (Origin, bool) {
+	var l, ok = o[k]
+	if !ok || len(l) == 0 {
+		return Origin{}, false
+	}
+
+	var or = l[0].export(k)
+	if len(l) > 1 {
+		or.Shadowed = make([]Origin, len(l)-1)
+		for i, oo := range l[1:] {
+			or.Shadowed[i] = oo.export(k)
+		}
+	}
+	return or, true
+}
+
+// Origin returns the origin of the key k, the loaders which also set k are listed in Shadowed.
+// It returns false if the key is not set.
+func (c *S) Origin(k string) (Origin, bool) {
+	k = c.canonical(k)
+	return c.o.Load().(origins).origin(k)
+}
+
+// Explain returns the origins of all keys in the global store
+func Explain() []Origin {
+	return instance().Explain()
+}
+
+// Explain returns the origins of all keys in the store sorted by key
+func (c *S) Explain() []Origin {
+	var o = c.o.Load().(origins)
+	var keys = make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var res = make([]Origin, 0, len(keys))
+	for _, k := range keys {
+		if or, ok := o.origin(k); ok {
+			res = append(res, or)
+		}
+	}
+	return res
+}
@@ -0,0 +1,76 @@
+package konfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type sourceLoader struct {
+	DummyLoader
+	name   string
+	source string
+}
+
+func (s *sourceLoader) Name() string {
+	return s.name
+}
+
+func (s *sourceLoader) Source() string {
+	return s.source
+}
+
+func TestOrigin(t *testing.T) {
+	t.Run(
+		"loaders and set",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.RegisterLoader(
+				&sourceLoader{
+					DummyLoader: DummyLoader{
+						DataToLoad: [][2]string{{"db.host", "file"}, {"db.port", "5432"}},
+					},
+					name:   "file",
+					source: "./config.json",
+				},
+			)
+			c.RegisterLoader(
+				&sourceLoader{
+					DummyLoader: DummyLoader{
+						DataToLoad: [][2]string{{"db.host", "env"}},
+					},
+					name: "env",
+				},
+			)
+
+			require.Nil(t, c.Load())
+
+			var o, ok = c.Origin("db.host")
+			require.True(t, ok)
+			require.Equal(t, "db.host", o.Key)
+			require.Equal(t, "env", o.Loader)
+			require.False(t, o.LoadedAt.IsZero())
+			require.Len(t, o.Shadowed, 1)
+			require.Equal(t, "file", o.Shadowed[0].Loader)
+			require.Equal(t, "./config.json", o.Shadowed[0].Source)
+
+			o, ok = c.Origin("db.port")
+			require.True(t, ok)
+			require.Equal(t, "file", o.Loader)
+			require.Empty(t, o.Shadowed)
+
+			c.Set("db.port", 1)
+			o, _ = c.Origin("db.port")
+			require.Equal(t, OriginSet, o.Loader)
+			require.Equal(t, "file", o.Shadowed[0].Loader)
+
+			_, ok = c.Origin("foo")
+			require.False(t, ok)
+
+			var e = c.Explain()
+			require.Len(t, e, 2)
+			require.Equal(t, "db.host", e[0].Key)
+			require.Equal(t, "db.port", e[1].Key)
+		},
+	)
+}
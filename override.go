@@ -0,0 +1,159 @@
+package konfig
+
+import "time"
+
+// override is a value set with Override, it wins over the values of all loaders
+type override struct {
+	v     interface{}
+	at    time.Time
+	timer *time.Timer
+}
+
+// Override sets the key k to the value v in the global store until the ttl expires or the override is cleared
+func Override(k string, v interface{}, ttl time.Duration) error {
+	return instance().Override(k, v, ttl)
+}
+
+// Override sets the key k to the value v until the ttl expires or the override is cleared with ClearOverride.
+// The override wins over the values of all loaders. If ttl is 0, the override never expires.
+// The new values are checked as a load, change hooks and key hooks run when the override is set and when it expires.
+// An override always expires, even if the values without it fail the checks: see Health.
+func (c *S) Override(k string, v interface{}, ttl time.Duration) error {
+	k = c.canonical(k)
+	c.mut.Lock()
+
+	var o = &override{v: v, at: time.Now()}
+	var prev, ok = c.overrides[k]
+
+	var overrides = c.overrides
+	c.overrides = make(map[string]*override, len(overrides)+1)
+	for kk, oo := range overrides {
+		c.overrides[kk] = oo
+	}
+	c.overrides[k] = o
+
+	var cs, err = c.applyLocked(nil, nil, OriginOverride, false)
+	if err != nil {
+		c.overrides = overrides
+		c.mut.Unlock()
+		return err
+	}
+
+	if ok && prev.timer != nil {
+		prev.timer.Stop()
+	}
+	if ttl > 0 {
+		o.timer = time.AfterFunc(ttl, func() {
+			c.expireOverride(k, o)
+		})
+	}
+
+	var changeHooks = c.changeHooks
+	c.mut.Unlock()
+
+	return c.runChangeHooks(changeHooks, cs)
+}
+
+// ClearOverride removes the override of the key k from the global store
+func ClearOverride(k string) error {
+	return instance().ClearOverride(k)
+}
+
+// ClearOverride removes the override of the key k, the key gets back the value of the loaders.
+// Change hooks and key hooks run as when the override expires.
+func (c *S) ClearOverride(k string) error {
+	k = c.canonical(k)
+	c.mut.Lock()
+
+	var o, ok = c.overrides[k]
+	if !ok {
+		c.mut.Unlock()
+		return nil
+	}
+
+	return c.clearOverride(k, o, false)
+}
+
+// expireOverride clears the override o of the key k if it is still in place.
+// An expired override is always removed: if the values without it fail the checks they are committed
+// nonetheless, the error is logged and the store reports it in its health until valid values are committed.
+func (c *S) expireOverride(k string, o *override) {
+	c.mut.Lock()
+
+	if c.overrides[k] != o {
+		c.mut.Unlock()
+		return
+	}
+
+	if err := c.clearOverride(k, o, true); err != nil {
+		c.cfg.Logger.Get().Error("Error while expiring override: " + err.Error())
+	}
+}
+
+// clearOverride removes the override o of the key k and applies the new values,
+// if force is true the values are committed even if they fail the checks.
+// It must be called with the lock held and releases it.
+func (c *S) clearOverride(k string, o *override, force bool) error {
+	var overrides = c.overrides
+	c.removeOverride(k)
+
+	var cs, err = c.applyLockedForce(nil, nil, OriginOverride, false, force)
+	if err != nil && !force {
+		c.overrides = overrides
+		c.mut.Unlock()
+		return err
+	}
+	if o.timer != nil {
+		o.timer.Stop()
+	}
+
+	var changeHooks = c.changeHooks
+	c.mut.Unlock()
+
+	if hErr := c.runChangeHooks(changeHooks, cs); hErr != nil {
+		return hErr
+	}
+	return err
+}
+
+// removeOverride removes the override of the key k, it returns the removed override
+func (c *S) removeOverride(k string) *override {
+	var o, ok = c.overrides[k]
+	if !ok {
+		return nil
+	}
+
+	var overrides = make(map[string]*override, len(c.overrides))
+	for kk, oo := range c.overrides {
+		if kk != k {
+			overrides[kk] = oo
+		}
+	}
+	c.overrides = overrides
+	return o
+}
+
+// stopOverrides stops the timers of all overrides, they don't expire anymore
+func (c *S) stopOverrides() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	for _, o := range c.overrides {
+		if o.timer != nil {
+			o.timer.Stop()
+		}
+	}
+}
+
+// runChangeHooks runs the change hooks and key hooks with the changes cs
+func (c *S) runChangeHooks(changeHooks changeHooks, cs ChangeSet) error {
+	if err := changeHooks.run(cs, nil); err != nil {
+		return err
+	}
+
+	if cs.Len() != 0 && c.keyHooks != nil {
+		return c.keyHooks.runForKeys(cs.Keys(), c)
+	}
+
+	return nil
+}
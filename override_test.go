@@ -0,0 +1,203 @@
+package konfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelete(t *testing.T) {
+	t.Run(
+		"until the next load of the loader",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &valuesLoader{values: Values{"foo": "bar"}}
+			var cl = c.RegisterLoader(l)
+			var other = c.RegisterLoader(&valuesLoader{values: Values{"other": "value"}})
+			require.Nil(t, c.Load())
+
+			var changes []ChangeSet
+			c.OnChange("foo", func(cs ChangeSet) error {
+				changes = append(changes, cs)
+				return nil
+			})
+
+			c.Set("baz", "qux")
+			require.Nil(t, c.Delete("baz"))
+			require.Nil(t, c.Delete("foo"))
+			require.Nil(t, c.Delete("unknown"))
+			require.False(t, c.Exists("baz"))
+			require.False(t, c.Exists("foo"))
+			var _, ok = c.Origin("foo")
+			require.False(t, ok)
+			require.Len(t, changes, 1)
+			require.Equal(t, OriginDelete, changes[0].Loader)
+			require.Equal(t, []Change{{Key: "foo", Old: "bar"}}, changes[0].Removed)
+
+			// loads of other loaders don't restore the key
+			require.Nil(t, c.loaderLoadRetry(context.Background(), other.loaderWatcher, 0))
+			require.False(t, c.Exists("foo"))
+
+			// the loader sets the key again on its next load, the deleted set value doesn't come back
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, "bar", c.Get("foo"))
+			require.False(t, c.Exists("baz"))
+		},
+	)
+
+	t.Run(
+		"strict keys",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.RegisterLoader(&valuesLoader{values: Values{"foo": "bar"}})
+			c.Strict("foo")
+			require.Nil(t, c.Load())
+
+			require.NotNil(t, c.Delete("foo"))
+			require.Equal(t, "bar", c.Get("foo"))
+		},
+	)
+}
+
+func TestOverride(t *testing.T) {
+	t.Run(
+		"wins over loaders",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &valuesLoader{values: Values{"feature": false}}
+			var cl = c.RegisterLoader(l)
+			require.Nil(t, c.Load())
+
+			var changes []ChangeSet
+			c.OnChange("feature", func(cs ChangeSet) error {
+				changes = append(changes, cs)
+				return nil
+			})
+			var ranKeyHook int
+			c.RegisterKeyHook("feature", func(Store) error {
+				ranKeyHook++
+				return nil
+			})
+
+			require.Nil(t, c.Override("feature", true, 0))
+			require.True(t, c.Bool("feature"))
+			var o, _ = c.Origin("feature")
+			require.Equal(t, OriginOverride, o.Loader)
+			require.Len(t, o.Shadowed, 1)
+
+			c.Set("feature", false)
+			require.True(t, c.Bool("feature"))
+
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.True(t, c.Bool("feature"))
+
+			require.Nil(t, c.ClearOverride("feature"))
+			require.False(t, c.Bool("feature"))
+			require.Nil(t, c.ClearOverride("feature"))
+
+			require.Equal(t, 2, ranKeyHook)
+			require.Len(t, changes, 2)
+			require.Equal(t, OriginOverride, changes[0].Loader)
+			require.Equal(t, []Change{{Key: "feature", Old: false, New: true}}, changes[0].Updated)
+		},
+	)
+
+	t.Run(
+		"expires",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.Set("feature", false)
+
+			var expired = make(chan struct{})
+			c.RegisterKeyHook("feature", func(s Store) error {
+				if !s.Bool("feature") {
+					close(expired)
+				}
+				return nil
+			})
+
+			require.Nil(t, c.Override("feature", true, 10*time.Millisecond))
+			require.True(t, c.Bool("feature"))
+
+			select {
+			case <-expired:
+			case <-time.After(time.Second):
+				t.Fatal("override did not expire")
+			}
+			require.False(t, c.Bool("feature"))
+		},
+	)
+
+	t.Run(
+		"invalid",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var err = errors.New("invalid")
+			c.RegisterPreCommitHook(func(sn *Snapshot) error {
+				if sn.Exists("feature") {
+					return err
+				}
+				return nil
+			})
+
+			require.NotNil(t, c.Override("feature", true, 0))
+			require.False(t, c.Exists("feature"))
+		},
+	)
+
+	t.Run(
+		"expires into invalid values",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &valuesLoader{values: Values{"port": 8080}}
+			var cl = c.RegisterLoader(l)
+			c.RegisterPreCommitHook(func(sn *Snapshot) error {
+				if sn.Int("port") == 0 {
+					return errors.New("invalid port")
+				}
+				return nil
+			})
+			require.Nil(t, c.Load())
+
+			var expired = make(chan struct{})
+			c.RegisterKeyHook("port", func(s Store) error {
+				if s.Int("port") == 0 {
+					close(expired)
+				}
+				return nil
+			})
+
+			require.Nil(t, c.Override("port", 9090, 10*time.Millisecond))
+
+			// the override hides the invalid value of the loader
+			l.values = Values{"port": 0}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, 9090, c.Int("port"))
+
+			// the override is dropped even though the values without it are invalid
+			select {
+			case <-expired:
+			case <-time.After(time.Second):
+				t.Fatal("override did not expire")
+			}
+			require.Equal(t, 0, c.Int("port"))
+			var o, _ = c.Origin("port")
+			require.Equal(t, "dummy", o.Loader)
+
+			var h = c.Health()
+			require.True(t, h.Invalid)
+			require.False(t, h.Ready)
+			require.Contains(t, h.InvalidError, "invalid port")
+
+			// valid values reset the health
+			l.values = Values{"port": 8080}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			h = c.Health()
+			require.False(t, h.Invalid)
+			require.True(t, h.Ready)
+		},
+	)
+}
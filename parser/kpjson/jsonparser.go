@@ -9,18 +9,30 @@ import (
 	"github.com/lalamove/konfig/parser/kpmap"
 )
 
+// Config is the config of the JSON parser
+type Config struct {
+	// FlattenArrays tells whether elements of arrays are added with indexed keys (servers.0.host)
+	// in addition to the whole array.
+	FlattenArrays bool
+}
+
 // Parser parses the given json io.Reader and adds values in dot.path notation into the konfig.Store
-var Parser = parser.Func(func(r io.Reader, s konfig.Values) error {
-	// unmarshal the JSON into  map[string]interface{}
-	var dec = json.NewDecoder(r)
+var Parser = New(&Config{})
+
+// New returns a new JSON parser with the given config
+func New(cfg *Config) parser.Func {
+	return parser.Func(func(r io.Reader, s konfig.Values) error {
+		// unmarshal the JSON into  map[string]interface{}
+		var dec = json.NewDecoder(r)
 
-	var d = make(map[string]interface{})
-	var err = dec.Decode(&d)
-	if err != nil {
-		return err
-	}
+		var d = make(map[string]interface{})
+		var err = dec.Decode(&d)
+		if err != nil {
+			return err
+		}
 
-	kpmap.PopFlatten(d, s)
+		kpmap.PopFlattenConfig(d, s, &kpmap.Config{FlattenArrays: cfg.FlattenArrays})
 
-	return nil
-})
+		return nil
+	})
+}
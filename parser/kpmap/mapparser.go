@@ -2,38 +2,71 @@ package kpmap
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/lalamove/konfig"
 )
 
-func traverseMapIface(m map[interface{}]interface{}, s konfig.Values, p string) {
+// Config is the config of the map flattener
+type Config struct {
+	// FlattenArrays tells whether elements of arrays are added with indexed keys (servers.0.host)
+	// in addition to the whole array.
+	FlattenArrays bool
+}
+
+type flattener struct {
+	cfg *Config
+	s   konfig.Values
+}
+
+func (f flattener) traverseMapIface(m map[interface{}]interface{}, p string) {
 	for k, v := range m {
 		var ks = fmt.Sprintf("%v", k)
-		switch vt := v.(type) {
-		case map[string]interface{}:
-			traverseMap(vt, s, p+ks+konfig.KeySep)
-		case map[interface{}]interface{}:
-			traverseMapIface(vt, s, p+ks+konfig.KeySep)
-		default:
-			s.Set(p+ks, v)
-		}
+		f.traverseValue(v, p+ks)
 	}
 }
 
-func traverseMap(m map[string]interface{}, s konfig.Values, p string) {
+func (f flattener) traverseMap(m map[string]interface{}, p string) {
 	for k, v := range m {
-		switch vt := v.(type) {
-		case map[string]interface{}:
-			traverseMap(vt, s, p+k+konfig.KeySep)
-		case map[interface{}]interface{}:
-			traverseMapIface(vt, s, p+k+konfig.KeySep)
-		default:
-			s.Set(p+k, v)
+		f.traverseValue(v, p+k)
+	}
+}
+
+func (f flattener) traverseSlice(l []interface{}, p string) {
+	for i, v := range l {
+		f.traverseValue(v, p+strconv.Itoa(i))
+	}
+}
+
+func (f flattener) traverseValue(v interface{}, k string) {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		f.traverseMap(vt, k+konfig.KeySep)
+	case map[interface{}]interface{}:
+		f.traverseMapIface(vt, k+konfig.KeySep)
+	case []interface{}:
+		f.s.Set(k, v)
+		if f.cfg.FlattenArrays {
+			f.traverseSlice(vt, k+konfig.KeySep)
 		}
+	case []map[string]interface{}:
+		f.s.Set(k, v)
+		if f.cfg.FlattenArrays {
+			for i, vv := range vt {
+				f.traverseMap(vv, k+konfig.KeySep+strconv.Itoa(i)+konfig.KeySep)
+			}
+		}
+	default:
+		f.s.Set(k, v)
 	}
 }
 
 // PopFlatten populates a konfig.Store by flatteing a map[string]interface{}
 func PopFlatten(m map[string]interface{}, s konfig.Values) {
-	traverseMap(m, s, "")
+	PopFlattenConfig(m, s, &Config{})
+}
+
+// PopFlattenConfig populates a konfig.Store by flatteing a map[string]interface{} with the given config
+func PopFlattenConfig(m map[string]interface{}, s konfig.Values, cfg *Config) {
+	flattener{cfg: cfg, s: s}.traverseMap(m, "")
 }
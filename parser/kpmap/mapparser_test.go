@@ -37,3 +37,58 @@ func TestMapPopFlatten(t *testing.T) {
 		v,
 	)
 }
+
+func TestMapPopFlattenArrays(t *testing.T) {
+	var m = map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{
+				"host": "a.local",
+			},
+			map[interface{}]interface{}{
+				"host": "b.local",
+			},
+		},
+		"tags":   []interface{}{"foo", []interface{}{"bar"}},
+		"tables": []map[string]interface{}{{"name": "foo"}},
+	}
+
+	t.Run(
+		"disabled",
+		func(t *testing.T) {
+			var v = konfig.Values{}
+			PopFlatten(m, v)
+			require.Equal(
+				t,
+				konfig.Values{
+					"servers": m["servers"],
+					"tags":    m["tags"],
+					"tables":  m["tables"],
+				},
+				v,
+			)
+		},
+	)
+
+	t.Run(
+		"enabled",
+		func(t *testing.T) {
+			var v = konfig.Values{}
+			PopFlattenConfig(m, v, &Config{FlattenArrays: true})
+			require.Equal(
+				t,
+				konfig.Values{
+					"servers":        m["servers"],
+					"servers.0.host": "a.local",
+					"servers.1.host": "b.local",
+					"tags":           m["tags"],
+					"tags.0":         "foo",
+					"tags.1":         []interface{}{"bar"},
+					"tags.1.0":       "bar",
+					"tables":         m["tables"],
+					"tables.0.name":  "foo",
+				},
+				v,
+			)
+		},
+	)
+}
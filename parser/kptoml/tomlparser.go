@@ -9,16 +9,28 @@ import (
 	"github.com/lalamove/konfig/parser/kpmap"
 )
 
+// Config is the config of the TOML parser
+type Config struct {
+	// FlattenArrays tells whether elements of arrays are added with indexed keys (servers.0.host)
+	// in addition to the whole array.
+	FlattenArrays bool
+}
+
 // Parser parses the given json io.Reader and adds values in dot.path notation into the konfig.Store
-var Parser = parser.Func(func(r io.Reader, s konfig.Values) error {
-	// unmarshal the JSON into  map[string]interface{}
-	var d = make(map[string]interface{})
-	var _, err = toml.DecodeReader(r, &d)
-	if err != nil {
-		return err
-	}
+var Parser = New(&Config{})
+
+// New returns a new TOML parser with the given config
+func New(cfg *Config) parser.Func {
+	return parser.Func(func(r io.Reader, s konfig.Values) error {
+		// unmarshal the JSON into  map[string]interface{}
+		var d = make(map[string]interface{})
+		var _, err = toml.DecodeReader(r, &d)
+		if err != nil {
+			return err
+		}
 
-	kpmap.PopFlatten(d, s)
+		kpmap.PopFlattenConfig(d, s, &kpmap.Config{FlattenArrays: cfg.FlattenArrays})
 
-	return nil
-})
+		return nil
+	})
+}
@@ -111,3 +111,44 @@ func TestParserErr(t *testing.T) {
 	)
 	require.NotNil(t, err)
 }
+
+func TestParserFlattenArrays(t *testing.T) {
+	var v = konfig.Values{}
+	var err = New(&Config{FlattenArrays: true}).Parse(
+		strings.NewReader(
+			`ports = [80, 81]
+
+[[clusters]]
+name = "a"
+
+  [[clusters.nodes]]
+  host = "a1.local"
+
+  [[clusters.nodes]]
+  host = "a2.local"
+
+[[clusters]]
+name = "b"
+
+  [[clusters.nodes]]
+  host = "b1.local"
+`,
+		),
+		v,
+	)
+	require.Nil(t, err)
+	require.Equal(t, int64(81), v["ports.1"])
+	require.Equal(t, "a", v["clusters.0.name"])
+	require.Equal(t, "a2.local", v["clusters.0.nodes.1.host"])
+	require.Equal(t, "b1.local", v["clusters.1.nodes.0.host"])
+
+	// the whole arrays are kept
+	require.Len(t, v["ports"], 2)
+	require.Len(t, v["clusters"], 2)
+	require.Len(t, v["clusters.0.nodes"], 2)
+
+	// arrays are not flattened by default
+	v = konfig.Values{}
+	require.Nil(t, Parser.Parse(strings.NewReader(`ports = [80, 81]`), v))
+	require.Equal(t, konfig.Values{"ports": []interface{}{int64(80), int64(81)}}, v)
+}
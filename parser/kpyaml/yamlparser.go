@@ -9,17 +9,29 @@ import (
 	yaml "gopkg.in/yaml.v2"
 )
 
+// Config is the config of the YAML parser
+type Config struct {
+	// FlattenArrays tells whether elements of arrays are added with indexed keys (servers.0.host)
+	// in addition to the whole array.
+	FlattenArrays bool
+}
+
 // Parser is the YAML Parser it implements parser.Parser
-var Parser = parser.Func(func(r io.Reader, s konfig.Values) error {
-	var dec = yaml.NewDecoder(r)
+var Parser = New(&Config{})
+
+// New returns a new YAML parser with the given config
+func New(cfg *Config) parser.Func {
+	return parser.Func(func(r io.Reader, s konfig.Values) error {
+		var dec = yaml.NewDecoder(r)
 
-	var d = make(map[string]interface{})
-	var err = dec.Decode(&d)
-	if err != nil {
-		return err
-	}
+		var d = make(map[string]interface{})
+		var err = dec.Decode(&d)
+		if err != nil {
+			return err
+		}
 
-	kpmap.PopFlatten(d, s)
+		kpmap.PopFlattenConfig(d, s, &kpmap.Config{FlattenArrays: cfg.FlattenArrays})
 
-	return nil
-})
+		return nil
+	})
+}
@@ -117,3 +117,21 @@ func TestParserErr(t *testing.T) {
 	)
 	require.NotNil(t, err)
 }
+
+func TestParserFlattenArrays(t *testing.T) {
+	var v = konfig.Values{}
+	var err = New(&Config{FlattenArrays: true}).Parse(
+		strings.NewReader(
+			`servers:
+  - host: "a.local"
+    port: 80
+  - host: "b.local"
+    port: 81`,
+		),
+		v,
+	)
+	require.Nil(t, err)
+	require.Equal(t, "a.local", v["servers.0.host"])
+	require.Equal(t, 81, v["servers.1.port"])
+	require.Len(t, v["servers"], 2)
+}
@@ -0,0 +1,176 @@
+package konfig
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// DefaultInitialDelay is the delay before the first retry of an ExponentialBackoff without InitialDelay
+const DefaultInitialDelay = 100 * time.Millisecond
+
+// jitter picks the jittered delays, it is seeded per process so that instances don't pick the same delays.
+// The source is not safe for concurrent use, it is guarded by jitterMut.
+var (
+	jitter    = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterMut sync.Mutex
+)
+
+// RetryPolicy decides whether a failed load is retried and how long to wait before retrying
+type RetryPolicy interface {
+	// Next returns the delay to wait before the retry number attempt (starting at 1) after the load failed with err,
+	// elapsed is the time since the first load. It returns false if the load must not be retried.
+	Next(attempt int, elapsed time.Duration, err error) (time.Duration, bool)
+}
+
+// RetryPolicyFunc is a function implementing RetryPolicy
+type RetryPolicyFunc func(attempt int, elapsed time.Duration, err error) (time.Duration, bool)
+
+// Next implements RetryPolicy
+func (f RetryPolicyFunc) Next(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+	return f(attempt, elapsed, err)
+}
+
+// RetryPolicyProvider is implemented by loaders providing their own retry policy
+type RetryPolicyProvider interface {
+	// RetryPolicy returns the retry policy of the loader
+	RetryPolicy() RetryPolicy
+}
+
+// permanentError is an error which must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err so that loads failing with it are not retried
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// IsRetryable tells whether a load failing with err can be retried.
+// Errors wrapped with Permanent and context errors are not retryable.
+func IsRetryable(err error) bool {
+	var pErr permanentError
+	if errors.As(err, &pErr) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// ExponentialBackoff is a RetryPolicy doubling the delay between each retry
+type ExponentialBackoff struct {
+	// InitialDelay is the delay before the first retry, default is DefaultInitialDelay
+	InitialDelay time.Duration
+	// MaxDelay is the maximum delay between two retries, 0 means no maximum
+	MaxDelay time.Duration
+	// Multiplier is the factor applied to the delay after each retry, default is 2
+	Multiplier float64
+	// Jitter enables full jitter: the delay is picked randomly between 0 and the computed delay,
+	// so that instances failing at the same time don't retry in lockstep
+	Jitter bool
+	// MaxRetry is the maximum number of retries, 0 means no maximum
+	MaxRetry int
+	// MaxElapsedTime is the maximum time since the first load after which the load is not retried, 0 means no maximum
+	MaxElapsedTime time.Duration
+	// Retryable tells whether the load can be retried after the error err, default is IsRetryable
+	Retryable func(err error) bool
+}
+
+// Next implements RetryPolicy
+func (b *ExponentialBackoff) Next(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+	var retryable = b.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	if !retryable(err) {
+		return 0, false
+	}
+	if b.MaxRetry > 0 && attempt > b.MaxRetry {
+		return 0, false
+	}
+
+	var multiplier = b.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	var initialDelay = b.InitialDelay
+	if initialDelay <= 0 {
+		initialDelay = DefaultInitialDelay
+	}
+	var delay = float64(initialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if b.MaxDelay > 0 && delay > float64(b.MaxDelay) {
+		delay = float64(b.MaxDelay)
+	}
+	if delay > math.MaxInt64 {
+		delay = math.MaxInt64
+	}
+
+	var d = time.Duration(delay)
+	if b.Jitter && d > 0 {
+		jitterMut.Lock()
+		d = time.Duration(jitter.Int63n(int64(d) + 1))
+		jitterMut.Unlock()
+	}
+
+	if b.MaxElapsedTime > 0 && elapsed+d > b.MaxElapsedTime {
+		return 0, false
+	}
+
+	return d, true
+}
+
+// loaderRetryPolicy is the default retry policy, it retries the loader MaxRetry times waiting RetryDelay between each retry
+type loaderRetryPolicy struct {
+	l Loader
+}
+
+// Next implements RetryPolicy
+func (p loaderRetryPolicy) Next(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+	if attempt > p.l.MaxRetry() {
+		return 0, false
+	}
+	var pErr permanentError
+	if errors.As(err, &pErr) {
+		return 0, false
+	}
+	return p.l.RetryDelay(), true
+}
+
+// WithRetryPolicy sets the retry policy of the loader, it takes precedence over the policy provided by the loader
+func (cl *ConfigLoader) WithRetryPolicy(p RetryPolicy) *ConfigLoader {
+	cl.s.mut.Lock()
+	defer cl.s.mut.Unlock()
+
+	cl.loaderWatcher.retryPolicy = p
+	return cl
+}
+
+// policy returns the retry policy of the loader
+func (lw *loaderWatcher) policy() RetryPolicy {
+	if lw.s != nil {
+		lw.s.mut.Lock()
+		defer lw.s.mut.Unlock()
+	}
+
+	if lw.retryPolicy != nil {
+		return lw.retryPolicy
+	}
+	if p, ok := lw.Loader.(RetryPolicyProvider); ok {
+		return p.RetryPolicy()
+	}
+	return loaderRetryPolicy{lw.Loader}
+}
@@ -0,0 +1,269 @@
+package konfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type flakyLoader struct {
+	valuesLoader
+	errs []error
+	runs int
+}
+
+func (l *flakyLoader) Load(v Values) error {
+	l.runs++
+	if l.runs <= len(l.errs) {
+		return l.errs[l.runs-1]
+	}
+	return l.valuesLoader.Load(v)
+}
+
+type policyLoader struct {
+	flakyLoader
+	policy RetryPolicy
+}
+
+func (l *policyLoader) RetryPolicy() RetryPolicy {
+	return l.policy
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	var err = errors.New("err")
+
+	t.Run(
+		"delay grows until max delay",
+		func(t *testing.T) {
+			var b = &ExponentialBackoff{
+				InitialDelay: 10 * time.Millisecond,
+				MaxDelay:     50 * time.Millisecond,
+			}
+			var delays []time.Duration
+			for i := 1; i <= 5; i++ {
+				var d, ok = b.Next(i, 0, err)
+				require.True(t, ok)
+				delays = append(delays, d)
+			}
+			require.Equal(
+				t,
+				[]time.Duration{
+					10 * time.Millisecond,
+					20 * time.Millisecond,
+					40 * time.Millisecond,
+					50 * time.Millisecond,
+					50 * time.Millisecond,
+				},
+				delays,
+			)
+		},
+	)
+
+	t.Run(
+		"zero value",
+		func(t *testing.T) {
+			var b = &ExponentialBackoff{}
+			var d, ok = b.Next(1, 0, err)
+			require.True(t, ok)
+			require.Equal(t, DefaultInitialDelay, d)
+
+			d, ok = b.Next(2, 0, err)
+			require.True(t, ok)
+			require.Equal(t, 2*DefaultInitialDelay, d)
+		},
+	)
+
+	t.Run(
+		"multiplier",
+		func(t *testing.T) {
+			var b = &ExponentialBackoff{
+				InitialDelay: 10 * time.Millisecond,
+				Multiplier:   3,
+			}
+			var d, ok = b.Next(3, 0, err)
+			require.True(t, ok)
+			require.Equal(t, 90*time.Millisecond, d)
+		},
+	)
+
+	t.Run(
+		"max retry",
+		func(t *testing.T) {
+			var b = &ExponentialBackoff{
+				InitialDelay: time.Millisecond,
+				MaxRetry:     2,
+			}
+			var _, ok = b.Next(2, 0, err)
+			require.True(t, ok)
+			_, ok = b.Next(3, 0, err)
+			require.False(t, ok)
+		},
+	)
+
+	t.Run(
+		"max elapsed time",
+		func(t *testing.T) {
+			var b = &ExponentialBackoff{
+				InitialDelay:   10 * time.Millisecond,
+				MaxElapsedTime: 100 * time.Millisecond,
+			}
+			var _, ok = b.Next(1, 80*time.Millisecond, err)
+			require.True(t, ok)
+			_, ok = b.Next(1, 95*time.Millisecond, err)
+			require.False(t, ok)
+		},
+	)
+
+	t.Run(
+		"jitter",
+		func(t *testing.T) {
+			var b = &ExponentialBackoff{
+				InitialDelay: 10 * time.Millisecond,
+				Jitter:       true,
+			}
+			for i := 0; i < 100; i++ {
+				var d, ok = b.Next(2, 0, err)
+				require.True(t, ok)
+				require.True(t, d >= 0 && d <= 20*time.Millisecond)
+			}
+		},
+	)
+
+	t.Run(
+		"retryable",
+		func(t *testing.T) {
+			var b = &ExponentialBackoff{InitialDelay: time.Millisecond}
+
+			var _, ok = b.Next(1, 0, Permanent(err))
+			require.False(t, ok)
+			_, ok = b.Next(1, 0, context.Canceled)
+			require.False(t, ok)
+
+			b.Retryable = func(err error) bool {
+				return err.Error() != "fatal"
+			}
+			_, ok = b.Next(1, 0, errors.New("fatal"))
+			require.False(t, ok)
+			_, ok = b.Next(1, 0, err)
+			require.True(t, ok)
+		},
+	)
+}
+
+func TestIsRetryable(t *testing.T) {
+	var err = errors.New("err")
+
+	require.True(t, IsRetryable(err))
+	require.False(t, IsRetryable(Permanent(err)))
+	require.False(t, IsRetryable(fmt.Errorf("wrapped: %w", Permanent(err))))
+	require.False(t, IsRetryable(context.DeadlineExceeded))
+	require.True(t, errors.Is(Permanent(err), err))
+	require.Nil(t, Permanent(nil))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	var err = errors.New("err")
+
+	t.Run(
+		"default policy uses max retry and retry delay",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &flakyLoader{
+				valuesLoader: valuesLoader{values: Values{"foo": "bar"}},
+				errs:         []error{err},
+			}
+			c.RegisterLoader(l)
+
+			require.Equal(t, err, c.Load())
+			require.Equal(t, 1, l.runs)
+		},
+	)
+
+	t.Run(
+		"permanent errors are not retried by the default policy",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &flakyLoader{
+				valuesLoader: valuesLoader{
+					DummyLoader: DummyLoader{maxRetry: 3},
+					values:      Values{"foo": "bar"},
+				},
+				errs: []error{Permanent(err)},
+			}
+			c.RegisterLoader(l)
+
+			require.Equal(t, Permanent(err), c.Load())
+			require.Equal(t, 1, l.runs)
+		},
+	)
+
+	t.Run(
+		"with retry policy",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &flakyLoader{
+				valuesLoader: valuesLoader{values: Values{"foo": "bar"}},
+				errs:         []error{err, err, err},
+			}
+			var attempts []int
+			c.RegisterLoader(l).WithRetryPolicy(RetryPolicyFunc(
+				func(attempt int, elapsed time.Duration, err error) (time.Duration, bool) {
+					attempts = append(attempts, attempt)
+					return time.Millisecond, true
+				},
+			))
+
+			require.Nil(t, c.Load())
+			require.Equal(t, []int{1, 2, 3}, attempts)
+			require.Equal(t, "bar", c.MustString("foo"))
+		},
+	)
+
+	t.Run(
+		"loader providing its retry policy",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &policyLoader{
+				flakyLoader: flakyLoader{
+					valuesLoader: valuesLoader{values: Values{"foo": "bar"}},
+					errs:         []error{err, err, err},
+				},
+				policy: &ExponentialBackoff{
+					InitialDelay: time.Millisecond,
+					MaxRetry:     2,
+				},
+			}
+			var cl = c.RegisterLoader(l)
+
+			require.Equal(t, err, c.Load())
+			require.Equal(t, 3, l.runs)
+
+			// the policy set on the loader takes precedence
+			cl.WithRetryPolicy(&ExponentialBackoff{InitialDelay: time.Millisecond})
+			l.runs = 0
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, 4, l.runs)
+		},
+	)
+
+	t.Run(
+		"context cancelled while waiting",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &flakyLoader{
+				valuesLoader: valuesLoader{values: Values{"foo": "bar"}},
+				errs:         []error{err},
+			}
+			var cl = c.RegisterLoader(l).WithRetryPolicy(&ExponentialBackoff{InitialDelay: time.Hour})
+			var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			require.Equal(t, context.DeadlineExceeded, c.loaderLoadRetry(ctx, cl.loaderWatcher, 0))
+			require.Equal(t, 1, l.runs)
+		},
+	)
+}
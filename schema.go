@@ -0,0 +1,371 @@
+package konfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/spf13/cast"
+)
+
+var _ Validator = (*Schema)(nil)
+
+// Type is the type of a config value in a Schema
+type Type string
+
+// Types supported by Schema. TypeInt accepts integers, whole numbers and strings formatted as integers,
+// TypeString accepts strings only, other types accept the values which can be converted to them.
+const (
+	TypeAny      Type = ""
+	TypeString   Type = "string"
+	TypeInt      Type = "integer"
+	TypeFloat    Type = "number"
+	TypeBool     Type = "boolean"
+	TypeDuration Type = "duration"
+	TypeSlice    Type = "array"
+	TypeMap      Type = "object"
+)
+
+// Schema is a Validator checking keys against rules
+type Schema struct {
+	rules map[string]*Rule
+}
+
+// NewSchema returns a new empty Schema
+func NewSchema() *Schema {
+	return &Schema{
+		rules: make(map[string]*Rule),
+	}
+}
+
+// Key returns the rule of the key k, creating it if it does not exist
+func (sc *Schema) Key(k string) *Rule {
+	if r, ok := sc.rules[k]; ok {
+		return r
+	}
+	var r = &Rule{key: k}
+	sc.rules[k] = r
+	return r
+}
+
+// Validate checks the values of the snapshot against the rules of the schema.
+// It returns a multierror with all violations.
+func (sc *Schema) Validate(sn *Snapshot) error {
+	var keys = make([]string, 0, len(sc.rules))
+	for k := range sc.rules {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var multiErr *multierror.Error
+	for _, k := range keys {
+		for _, err := range sc.rules[k].validate(sn) {
+			multiErr = multierror.Append(multiErr, err)
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
+
+type condition struct {
+	key    string
+	values []interface{}
+}
+
+// Rule is the set of constraints on the value of a key
+type Rule struct {
+	key        string
+	typ        Type
+	required   bool
+	requiredIf []condition
+	min        *float64
+	max        *float64
+	pattern    *regexp.Regexp
+	enum       []interface{}
+	funcs      []func(interface{}) error
+	secret     bool
+}
+
+// Type sets the type of the value
+func (r *Rule) Type(t Type) *Rule {
+	r.typ = t
+	return r
+}
+
+// Required makes the key mandatory
+func (r *Rule) Required() *Rule {
+	r.required = true
+	return r
+}
+
+// RequiredIf makes the key mandatory when the key k is set and, if values are given, equal to one of them
+func (r *Rule) RequiredIf(k string, values ...interface{}) *Rule {
+	r.requiredIf = append(r.requiredIf, condition{key: k, values: values})
+	return r
+}
+
+// Min sets the minimum of the value, converted to a float64
+func (r *Rule) Min(min float64) *Rule {
+	r.min = &min
+	return r
+}
+
+// Max sets the maximum of the value, converted to a float64
+func (r *Rule) Max(max float64) *Rule {
+	r.max = &max
+	return r
+}
+
+// Range sets the minimum and the maximum of the value
+func (r *Rule) Range(min, max float64) *Rule {
+	return r.Min(min).Max(max)
+}
+
+// Pattern sets a regular expression the value, converted to a string, must match.
+// It panics if the expression is invalid.
+func (r *Rule) Pattern(expr string) *Rule {
+	r.pattern = regexp.MustCompile(expr)
+	return r
+}
+
+// Enum sets the list of allowed values
+func (r *Rule) Enum(values ...interface{}) *Rule {
+	r.enum = values
+	return r
+}
+
+// Secret hides the value in validation errors.
+// The values of the secret keys of the store are hidden as well.
+func (r *Rule) Secret() *Rule {
+	r.secret = true
+	return r
+}
+
+// Func adds a custom validation function for the value
+func (r *Rule) Func(f func(interface{}) error) *Rule {
+	r.funcs = append(r.funcs, f)
+	return r
+}
+
+func (r *Rule) invalid(format string, args ...interface{}) error {
+	return &ValidationError{Key: r.key, Message: fmt.Sprintf(format, args...)}
+}
+
+func (r *Rule) validate(sn *Snapshot) []error {
+	if !sn.Exists(r.key) {
+		// an object is flattened into its subkeys, it is set if one of them is set
+		if sn.hasSubkeys(r.key) {
+			return nil
+		}
+		if r.required {
+			return []error{r.invalid("required")}
+		}
+		for _, cond := range r.requiredIf {
+			if cond.match(sn) {
+				return []error{r.invalid("required when '%s' is set", cond.key)}
+			}
+		}
+		return nil
+	}
+
+	var v = sn.Get(r.key)
+	var errs []error
+
+	// the value displayed in errors, secret values are never displayed
+	var secret = r.secret || (sn.secret != nil && sn.secret(r.key))
+	var dv = v
+	if secret {
+		dv = SecretMask
+	}
+
+	if err := r.typ.check(v); err != nil {
+		// other constraints are meaningless if the type is wrong,
+		// the error of the cast contains the value so it is not displayed for secrets
+		if secret {
+			return []error{r.invalid("not of type %s", r.typ)}
+		}
+		return []error{r.invalid("%s", err.Error())}
+	}
+
+	if r.min != nil || r.max != nil {
+		var f, err = cast.ToFloat64E(v)
+		if err != nil {
+			errs = append(errs, r.invalid("not a number"))
+		} else if r.min != nil && f < *r.min {
+			errs = append(errs, r.invalid("%v is lower than %v", dv, *r.min))
+		} else if r.max != nil && f > *r.max {
+			errs = append(errs, r.invalid("%v is greater than %v", dv, *r.max))
+		}
+	}
+
+	if r.pattern != nil && !r.pattern.MatchString(cast.ToString(v)) {
+		errs = append(errs, r.invalid("%v does not match %s", dv, r.pattern.String()))
+	}
+
+	if r.enum != nil && !contains(r.enum, v) {
+		errs = append(errs, r.invalid("%v is not one of %v", dv, r.enum))
+	}
+
+	for _, f := range r.funcs {
+		if err := f(v); err != nil {
+			errs = append(errs, r.invalid("%s", err.Error()))
+		}
+	}
+
+	return errs
+}
+
+func (cond condition) match(sn *Snapshot) bool {
+	if !sn.Exists(cond.key) {
+		return len(cond.values) == 0 && sn.hasSubkeys(cond.key)
+	}
+	return len(cond.values) == 0 || contains(cond.values, sn.Get(cond.key))
+}
+
+// hasSubkeys checks whether a key under the path k is set in the snapshot
+func (sn *Snapshot) hasSubkeys(k string) bool {
+	k = sn.canonical(k)
+	for kk := range sn.m {
+		if kk != k && hasKeyPrefix(kk, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains checks if v is in values, values are compared by their string representation
+func contains(values []interface{}, v interface{}) bool {
+	for _, vv := range values {
+		if reflect.DeepEqual(vv, v) || fmt.Sprint(vv) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t Type) check(v interface{}) error {
+	var err error
+	switch t {
+	case TypeString:
+		if _, ok := v.(string); !ok {
+			err = fmt.Errorf("%T is not a string", v)
+		}
+	case TypeInt:
+		err = checkInt(v)
+	case TypeFloat:
+		_, err = cast.ToFloat64E(v)
+	case TypeBool:
+		_, err = cast.ToBoolE(v)
+	case TypeDuration:
+		_, err = cast.ToDurationE(v)
+	case TypeSlice:
+		if v == nil || (reflect.TypeOf(v).Kind() != reflect.Slice && reflect.TypeOf(v).Kind() != reflect.Array) {
+			err = fmt.Errorf("%T is not an array", v)
+		}
+	case TypeMap:
+		_, err = cast.ToStringMapE(v)
+	}
+	if err != nil {
+		return fmt.Errorf("not of type %s: %v", t, err)
+	}
+	return nil
+}
+
+// checkInt checks that v is an integer or a whole number, strings must be formatted as integers
+func checkInt(v interface{}) error {
+	switch vv := v.(type) {
+	case bool:
+		return fmt.Errorf("%T is not an integer", v)
+	case float32:
+		if f := float64(vv); f != math.Trunc(f) {
+			return fmt.Errorf("%v is not a whole number", v)
+		}
+	case float64:
+		if vv != math.Trunc(vv) {
+			return fmt.Errorf("%v is not a whole number", v)
+		}
+	case string:
+		var _, err = strconv.ParseInt(vv, 0, 64)
+		return err
+	}
+	var _, err = cast.ToInt64E(v)
+	return err
+}
+
+type jsonSchema struct {
+	Type              Type                   `json:"type"`
+	Properties        map[string]*jsonSchema `json:"properties"`
+	Required          []string               `json:"required"`
+	DependentRequired map[string][]string    `json:"dependentRequired"`
+	Minimum           *float64               `json:"minimum"`
+	Maximum           *float64               `json:"maximum"`
+	Pattern           string                 `json:"pattern"`
+	Enum              []interface{}          `json:"enum"`
+	Format            string                 `json:"format"`
+}
+
+// ParseJSONSchema parses a JSON Schema document into a Schema.
+// Nested object properties are flattened into keys separated by KeySep.
+// Supported keywords are type, properties, required, dependentRequired, minimum, maximum, pattern, enum
+// and the format "duration".
+func ParseJSONSchema(r io.Reader) (*Schema, error) {
+	var js jsonSchema
+	if err := json.NewDecoder(r).Decode(&js); err != nil {
+		return nil, err
+	}
+
+	var sc = NewSchema()
+	if err := js.add(sc, ""); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+func (js *jsonSchema) add(sc *Schema, prefix string) error {
+	for _, k := range js.Required {
+		sc.Key(prefix + k).Required()
+	}
+	for k, deps := range js.DependentRequired {
+		for _, dep := range deps {
+			sc.Key(prefix + dep).RequiredIf(prefix + k)
+		}
+	}
+
+	for k, p := range js.Properties {
+		var key = prefix + k
+		// nested objects are flattened
+		if p.Properties != nil {
+			if err := p.add(sc, key+KeySep); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var r = sc.Key(key).Type(p.Type)
+		if p.Type == TypeString && p.Format == string(TypeDuration) {
+			r.Type(TypeDuration)
+		}
+		if p.Minimum != nil {
+			r.Min(*p.Minimum)
+		}
+		if p.Maximum != nil {
+			r.Max(*p.Maximum)
+		}
+		if p.Pattern != "" {
+			var re, err = regexp.Compile(p.Pattern)
+			if err != nil {
+				return err
+			}
+			r.pattern = re
+		}
+		if p.Enum != nil {
+			r.Enum(p.Enum...)
+		}
+	}
+	return nil
+}
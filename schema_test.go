@@ -0,0 +1,245 @@
+package konfig
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotOf(m s) *Snapshot {
+	return &Snapshot{m: m}
+}
+
+func TestSchema(t *testing.T) {
+	var sc = NewSchema()
+	sc.Key("port").Type(TypeInt).Range(1, 65535).Required()
+	sc.Key("host").Type(TypeString).Pattern(`^[a-z.]+$`)
+	sc.Key("env").Enum("dev", "prod")
+	sc.Key("timeout").Type(TypeDuration)
+	sc.Key("tls.cert").RequiredIf("tls.enabled", true)
+	sc.Key("servers").Type(TypeSlice)
+	sc.Key("name").Func(func(v interface{}) error {
+		if v == "root" {
+			return errors.New("reserved name")
+		}
+		return nil
+	})
+
+	var testCases = []struct {
+		name   string
+		values s
+		errs   []string
+	}{
+		{
+			name: "valid",
+			values: s{
+				"port":        "8080",
+				"host":        "localhost",
+				"env":         "dev",
+				"timeout":     "1s",
+				"tls.enabled": false,
+				"servers":     []interface{}{"a"},
+				"name":        "konfig",
+			},
+		},
+		{
+			name:   "required",
+			values: s{},
+			errs:   []string{"Err config 'port' is invalid: required"},
+		},
+		{
+			name: "all violations",
+			values: s{
+				"port":        70000,
+				"host":        "LOCALHOST",
+				"env":         "staging",
+				"timeout":     "abc",
+				"tls.enabled": "true",
+				"servers":     "a",
+				"name":        "root",
+			},
+			errs: []string{
+				"Err config 'env' is invalid: staging is not one of [dev prod]",
+				"Err config 'host' is invalid: LOCALHOST does not match ^[a-z.]+$",
+				"Err config 'name' is invalid: reserved name",
+				"Err config 'port' is invalid: 70000 is greater than 65535",
+				"Err config 'servers' is invalid: not of type array: string is not an array",
+				"Err config 'timeout' is invalid: not of type duration",
+				"Err config 'tls.cert' is invalid: required when 'tls.enabled' is set",
+			},
+		},
+		{
+			name: "whole numbers are integers",
+			values: s{
+				"port": 8080.0,
+				"host": "localhost",
+			},
+		},
+		{
+			name: "no coercion",
+			values: s{
+				"port": 1.5,
+				"host": 1,
+			},
+			errs: []string{
+				"Err config 'host' is invalid: not of type string: int is not a string",
+				"Err config 'port' is invalid: not of type integer: 1.5 is not a whole number",
+			},
+		},
+		{
+			name: "integer strings",
+			values: s{
+				"port": "1.5",
+			},
+			errs: []string{
+				"Err config 'port' is invalid: not of type integer",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(
+			testCase.name,
+			func(t *testing.T) {
+				var err = sc.Validate(snapshotOf(testCase.values))
+				if testCase.errs == nil {
+					require.Nil(t, err)
+					return
+				}
+				require.NotNil(t, err)
+				var errs = err.(*multierror.Error).Errors
+				require.Len(t, errs, len(testCase.errs))
+				for i, e := range errs {
+					require.True(t, strings.HasPrefix(e.Error(), testCase.errs[i]), e.Error())
+				}
+			},
+		)
+	}
+}
+
+func TestParseJSONSchema(t *testing.T) {
+	var sc, err = ParseJSONSchema(strings.NewReader(`{
+		"type": "object",
+		"required": ["port", "db"],
+		"properties": {
+			"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+			"env": {"type": "string", "enum": ["dev", "prod"]},
+			"db": {
+				"type": "object",
+				"required": ["host"],
+				"dependentRequired": {"user": ["password"]},
+				"properties": {
+					"host": {"type": "string", "pattern": "^[a-z]+$"},
+					"timeout": {"type": "string", "format": "duration"}
+				}
+			}
+		}
+	}`))
+	require.Nil(t, err)
+
+	require.Nil(t, sc.Validate(snapshotOf(s{"port": 80, "env": "dev", "db.host": "localhost", "db.timeout": "1s"})))
+
+	err = sc.Validate(snapshotOf(s{"port": 0, "env": "staging", "db.user": "root", "db.timeout": "abc"}))
+	require.NotNil(t, err)
+	require.Len(t, err.(*multierror.Error).Errors, 5)
+
+	// a required object is set if one of its subkeys is set
+	err = sc.Validate(snapshotOf(s{"port": 80}))
+	require.NotNil(t, err)
+	require.Len(t, err.(*multierror.Error).Errors, 2)
+	require.Contains(t, err.Error(), "config 'db' is invalid: required")
+
+	_, err = ParseJSONSchema(strings.NewReader(`{"properties": {"a": {"pattern": "("}}}`))
+	require.NotNil(t, err)
+
+	_, err = ParseJSONSchema(strings.NewReader(`{`))
+	require.NotNil(t, err)
+}
+
+func TestRegisterValidator(t *testing.T) {
+	var c = New(DefaultConfig())
+	var l = &valuesLoader{values: Values{"port": 8080}}
+	var cl = c.RegisterLoader(l)
+
+	var sc = NewSchema()
+	sc.Key("port").Type(TypeInt).Range(1, 65535).Required()
+	c.RegisterValidator(sc)
+
+	require.Nil(t, c.Load())
+
+	// invalid reloads are rejected
+	l.values = Values{"port": 0}
+	require.NotNil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+	require.Equal(t, 8080, c.Int("port"))
+
+	l.values = Values{}
+	require.NotNil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+	require.Equal(t, 8080, c.Int("port"))
+
+	// invalid initial load fails and the values are not committed
+	c = New(DefaultConfig())
+	l = &valuesLoader{values: Values{"port": "abc"}}
+	cl = c.RegisterLoader(l)
+	c.RegisterValidator(sc)
+	require.NotNil(t, c.Load())
+	require.False(t, c.Exists("port"))
+
+	// later loads are validated
+	l.values = Values{"port": 0}
+	require.NotNil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+	require.False(t, c.Exists("port"))
+}
+
+func TestRegisterValidatorSet(t *testing.T) {
+	var c = New(DefaultConfig())
+	var l = &valuesLoader{values: Values{"port": 8080}}
+	var other = &valuesLoader{values: Values{"host": "localhost"}}
+	c.RegisterLoader(l)
+	var cl = c.RegisterLoader(other)
+
+	var sc = NewSchema()
+	sc.Key("port").Type(TypeInt)
+	c.RegisterValidator(sc)
+
+	require.Nil(t, c.Load())
+
+	// an invalid value is not set
+	require.NotNil(t, c.Set("port", "abc"))
+	require.Equal(t, 8080, c.Get("port"))
+
+	// reloads of other loaders are not blocked by it
+	other.values = Values{"host": "remote"}
+	require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+	require.Equal(t, "remote", c.String("host"))
+	require.Equal(t, 8080, c.Get("port"))
+
+	// a valid value is set
+	require.Nil(t, c.Set("port", 9090))
+	require.Equal(t, 9090, c.Get("port"))
+}
+
+func TestSchemaSecret(t *testing.T) {
+	var sc = NewSchema()
+	sc.Key("db.password").Type(TypeInt).Secret()
+	sc.Key("api.token").Type(TypeDuration).Enum("1s")
+
+	var err = sc.Validate(snapshotOf(s{"db.password": "hunter2"}))
+	require.NotNil(t, err)
+	require.Equal(t, "Err config 'db.password' is invalid: not of type integer", err.(*multierror.Error).Errors[0].Error())
+	require.NotContains(t, err.Error(), "hunter2")
+
+	// keys set as secret on the store are hidden as well
+	var c = New(DefaultConfig())
+	c.Secret("api")
+	c.RegisterLoader(&valuesLoader{values: Values{"api.token": "s3cr3t"}})
+	c.RegisterValidator(sc)
+
+	err = c.Load()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "'api.token' is invalid: not of type duration")
+	require.NotContains(t, err.Error(), "s3cr3t")
+}
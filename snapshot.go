@@ -0,0 +1,215 @@
+package konfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrSnapshotNotFound is the error returned when rolling back to a version which is not in the history
+var ErrSnapshotNotFound = errors.New("Err snapshot not found")
+
+// OriginRollback is the loader name set in the ChangeSet of a rollback
+const OriginRollback = "rollback"
+
+const defaultMaxSnapshots = 10
+
+// Snapshot is an immutable state of the store
+type Snapshot struct {
+	// Version is the version of the store, it increases every time the values of the store are replaced
+	Version uint64
+	// Hash is a hash of the keys and values of the snapshot
+	Hash string
+	// CreatedAt is the time at which the snapshot was taken
+	CreatedAt time.Time
+
+	m       s
+	aliases aliases
+	key     func(k string) string
+	layers  map[*loaderWatcher]Values
+	sets    Values
+	// secret checks if a key is secret in the store, it is set while the snapshot is validated
+	secret func(k string) bool
+}
+
+// Values returns a copy of the values of the snapshot
+func (sn *Snapshot) Values() map[string]interface{} {
+	var m = make(map[string]interface{}, len(sn.m))
+	for k, v := range sn.m {
+		m[k] = v
+	}
+	return m
+}
+
+// hash returns a sha256 hash of the keys and values of m
+func (m s) hash() string {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var h = sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%q=%#v;", k, m[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// newSnapshot returns the snapshot of the next version of the store with the values nm
+func (c *S) newSnapshot(nm s, t time.Time) *Snapshot {
+	return &Snapshot{
+		Version:   c.Snapshot().Version + 1,
+		Hash:      nm.hash(),
+		CreatedAt: t,
+		m:         nm,
+		aliases:   c.aliases.Load().(aliases),
+		key:       c.cfg.KeyNormalizer,
+	}
+}
+
+// commit stores the values of sn and no as the new state of the store and records sn in the history.
+// If the values of sn are the values of the current snapshot, only the origins are stored: the version
+// is not increased and nothing is added to the history. It returns whether sn was committed.
+// It must be called with c.mut locked.
+func (c *S) commit(sn *Snapshot, no origins) bool {
+	c.o.Store(no)
+	if sn.Hash == c.Snapshot().Hash {
+		return false
+	}
+
+	sn.layers = make(map[*loaderWatcher]Values, len(c.layers))
+	for _, wl := range c.layers {
+		sn.layers[wl] = wl.values
+	}
+	sn.sets = c.sets
+
+	var max = c.cfg.MaxSnapshots
+	if max == 0 {
+		max = defaultMaxSnapshots
+	}
+	if max > 0 {
+		c.history = append(c.history, sn)
+		if len(c.history) > max {
+			c.history = c.history[len(c.history)-max:]
+		}
+	}
+
+	c.m.Store(sn.m)
+	c.sn.Store(sn)
+	return true
+}
+
+// Snapshot returns the current snapshot of the store
+func (c *S) Snapshot() *Snapshot {
+	return c.sn.Load().(*Snapshot)
+}
+
+// Version returns the current version of the global store
+func Version() uint64 {
+	return instance().Version()
+}
+
+// Version returns the current version of the store
+func (c *S) Version() uint64 {
+	return c.Snapshot().Version
+}
+
+// History returns the snapshots kept in the history of the global store
+func History() []*Snapshot {
+	return instance().History()
+}
+
+// History returns the snapshots kept in the history of the store, from the oldest to the newest
+func (c *S) History() []*Snapshot {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	var h = make([]*Snapshot, len(c.history))
+	copy(h, c.history)
+	return h
+}
+
+// Rollback rolls the global store back to the snapshot with the given version
+func Rollback(version uint64) error {
+	return instance().Rollback(version)
+}
+
+// Rollback restores the values of the loaders and of Set from the snapshot with the given version.
+// The rollback is recorded as a new version and runs the change hooks, key hooks and subscribers as a reload does.
+// The values of the snapshot are checked as loaded values are, if strict keys, validators, bound values
+// or pre-commit hooks reject them the store is left untouched and the error is returned.
+// The rolled back values are kept until the next reload of a loader.
+func (c *S) Rollback(version uint64) error {
+	c.mut.Lock()
+
+	var sn *Snapshot
+	for _, h := range c.history {
+		if h.Version == version {
+			sn = h
+			break
+		}
+	}
+	if sn == nil {
+		c.mut.Unlock()
+		return ErrSnapshotNotFound
+	}
+
+	var m = c.m.Load().(s)
+
+	// restore the values of the loaders, loaders which loaded after the snapshot was taken
+	// are removed from the layers
+	var layers = make([]*loaderWatcher, 0, len(c.layers))
+	var values = make(map[*loaderWatcher]Values, len(c.layers))
+	for _, wl := range c.layers {
+		values[wl] = wl.values
+		if v, ok := sn.layers[wl]; ok {
+			wl.values = v
+			layers = append(layers, wl)
+		}
+	}
+	var prevLayers, sets = c.layers, c.sets
+	c.layers = layers
+	c.sets = sn.sets
+
+	// if the rolled back values fail to resolve or to pass the checks, the store is left untouched
+	var restore = func() {
+		for wl, v := range values {
+			wl.values = v
+		}
+		c.layers, c.sets = prevLayers, sets
+	}
+
+	var t = time.Now()
+	var raw, no = c.merge(nil, t)
+
+	var nm, err = c.interpolate(raw)
+	if err != nil {
+		restore()
+		c.mut.Unlock()
+		return err
+	}
+
+	// the rolled back values go through the same checks as loaded values: strict keys,
+	// validators, bound values and pre-commit hooks
+	var nsn = c.newSnapshot(nm, t)
+	if err := c.check(nsn, raw, true); err != nil {
+		restore()
+		c.mut.Unlock()
+		return err
+	}
+
+	c.raw = raw
+	c.commit(nsn, no)
+
+	var cs = diff(m, nm)
+	cs.Loader = OriginRollback
+	c.publish(&cs, nil, t)
+	c.mut.Unlock()
+
+	// the hooks run as for a reload: change hooks, key hooks, then the hooks of all loaders
+	return c.runReloadHooks(cs)
+}
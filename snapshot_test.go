@@ -0,0 +1,161 @@
+package konfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Run(
+		"versions and history",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.MaxSnapshots = 2
+			var c = New(cfg)
+			var l = &valuesLoader{values: Values{"foo": "bar"}}
+			var cl = c.RegisterLoader(l)
+
+			require.Equal(t, uint64(0), c.Version())
+			require.Nil(t, c.Load())
+			require.Equal(t, uint64(1), c.Version())
+
+			var sn = c.Snapshot()
+			require.Equal(t, map[string]interface{}{"foo": "bar"}, sn.Values())
+			require.NotEmpty(t, sn.Hash)
+
+			c.Set("baz", 1)
+			require.Equal(t, uint64(2), c.Version())
+			require.NotEqual(t, sn.Hash, c.Snapshot().Hash)
+
+			// a reload with the same values does not create a new version
+			for i := 0; i < 3; i++ {
+				require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			}
+			require.Equal(t, uint64(2), c.Version())
+
+			l.values = Values{"foo": "qux"}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, uint64(3), c.Version())
+
+			var h = c.History()
+			require.Len(t, h, 2)
+			require.Equal(t, uint64(2), h[0].Version)
+			require.Equal(t, uint64(3), h[1].Version)
+
+			// the snapshot is not modified by later changes
+			require.Equal(t, map[string]interface{}{"foo": "bar"}, sn.Values())
+		},
+	)
+
+	t.Run(
+		"no history",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.MaxSnapshots = -1
+			var c = New(cfg)
+			c.Set("foo", "bar")
+			require.Equal(t, uint64(1), c.Version())
+			require.Len(t, c.History(), 0)
+			require.Equal(t, ErrSnapshotNotFound, c.Rollback(1))
+		},
+	)
+}
+
+func TestRollback(t *testing.T) {
+	var cfg = DefaultConfig()
+	cfg.SubscribeBuffer = 2
+	var c = New(cfg)
+	var l = &valuesLoader{values: Values{"db.host": "good", "db.port": 5432}}
+	var ranLoaderHook int
+	var cl = c.RegisterLoader(l, func(Store) error {
+		ranLoaderHook++
+		return nil
+	})
+
+	require.Nil(t, c.Load())
+	var good = c.Version()
+
+	var changes []ChangeSet
+	c.OnChange("db", func(cs ChangeSet) error {
+		changes = append(changes, cs)
+		return nil
+	})
+	var ranKeyHook int
+	c.RegisterKeyHook("db.host", func(Store) error {
+		ranKeyHook++
+		return nil
+	})
+	var events, cancel = c.Subscribe("db")
+	defer cancel()
+
+	l.values = Values{"db.host": "bad"}
+	require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+	require.Equal(t, "bad", c.MustString("db.host"))
+	require.False(t, c.Exists("db.port"))
+
+	require.Equal(t, ErrSnapshotNotFound, c.Rollback(100))
+
+	ranLoaderHook = 0
+	require.Nil(t, c.Rollback(good))
+	require.Equal(t, 1, ranLoaderHook)
+	require.Equal(t, "good", c.MustString("db.host"))
+	require.Equal(t, 5432, c.MustInt("db.port"))
+	require.Equal(t, good+2, c.Version())
+	require.Equal(t, c.History()[0].Hash, c.Snapshot().Hash)
+
+	require.Len(t, changes, 2)
+	require.Equal(t, OriginRollback, changes[1].Loader)
+	require.Equal(t, []Change{{Key: "db.port", New: 5432}}, changes[1].Added)
+	require.Equal(t, []Change{{Key: "db.host", Old: "bad", New: "good"}}, changes[1].Updated)
+	require.Equal(t, 2, ranKeyHook)
+
+	var e = <-events
+	require.Equal(t, "dummy", e.Loader)
+	e = <-events
+	require.Equal(t, OriginRollback, e.Loader)
+
+	var or, ok = c.Origin("db.host")
+	require.True(t, ok)
+	require.Equal(t, "dummy", or.Loader)
+}
+
+func TestRollbackChecks(t *testing.T) {
+	var c = New(DefaultConfig())
+	var l = &valuesLoader{values: Values{"db.host": "good", "db.port": 5432}}
+	var cl = c.RegisterLoader(l)
+
+	require.Nil(t, c.Load())
+	var good = c.Version()
+
+	l.values = Values{"db.host": "bad"}
+	require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+
+	c.RegisterValidator(ValidatorFunc(func(sn *Snapshot) error {
+		if sn.Exists("db.port") {
+			return &ValidationError{Key: "db.port", Message: "not allowed"}
+		}
+		return nil
+	}))
+
+	require.NotNil(t, c.Rollback(good))
+	require.Equal(t, "bad", c.MustString("db.host"))
+	require.False(t, c.Exists("db.port"))
+	require.Equal(t, good+1, c.Version())
+
+	c.validators = nil
+	c.RegisterPreCommitHook(func(sn *Snapshot) error {
+		return errors.New("vetoed")
+	})
+
+	require.NotNil(t, c.Rollback(good))
+	require.Equal(t, "bad", c.MustString("db.host"))
+
+	// the values of the loader were restored, merging them again does not bring back the snapshot
+	c.preCommitHooks = nil
+	c.Set("db.name", "konfig")
+	require.Equal(t, "bad", c.MustString("db.host"))
+	require.False(t, c.Exists("db.port"))
+}
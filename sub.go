@@ -0,0 +1,578 @@
+package konfig
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lalamove/nui/ngetter"
+	"github.com/lalamove/nui/nlogger"
+)
+
+// ErrReadOnly is the error returned when trying to modify a read only store
+var ErrReadOnly = errors.New("Err store is read only")
+
+var _ Store = (*subStore)(nil)
+
+// subStore is a read only view of the keys under a path prefix of a store
+type subStore struct {
+	c      *S
+	prefix string
+}
+
+// Sub returns a live read only view of the keys of the global store under the path prefix p,
+// the prefix is stripped from the keys.
+func Sub(p string) Store {
+	return instance().Sub(p)
+}
+
+// Sub returns a live read only view of the keys under the path prefix p, the prefix is stripped from the keys.
+// Methods modifying the store return ErrReadOnly, methods which don't return an error log it and do nothing.
+func (c *S) Sub(p string) Store {
+	return &subStore{c: c, prefix: p}
+}
+
+// key returns the key k of the parent store
+func (c *subStore) key(k string) string {
+	if c.prefix == "" {
+		return k
+	}
+	if k == "" {
+		return c.prefix
+	}
+	return c.prefix + KeySep + k
+}
+
+// strip returns the key k of the parent store stripped from the prefix
+func (c *subStore) strip(k string) string {
+	if kk, ok := c.stripKey(k); ok {
+		return kk
+	}
+	return k
+}
+
+// stripKey returns the key k of the parent store stripped from the prefix and whether k is under the prefix,
+// the keys of the parent store are canonical so the prefix is canonicalized as well
+func (c *subStore) stripKey(k string) (string, bool) {
+	return stripPrefix(k, c.c.canonical(c.prefix))
+}
+
+func (c *subStore) stripChanges(l []Change) []Change {
+	if l == nil {
+		return nil
+	}
+	var r = make([]Change, len(l))
+	for i, ch := range l {
+		ch.Key = c.strip(ch.Key)
+		r[i] = ch
+	}
+	return r
+}
+
+func (c *subStore) stripChangeSet(cs ChangeSet) ChangeSet {
+	cs.Added = c.stripChanges(cs.Added)
+	cs.Updated = c.stripChanges(cs.Updated)
+	cs.Removed = c.stripChanges(cs.Removed)
+	return cs
+}
+
+func (c *subStore) stripOrigin(o Origin) Origin {
+	o.Key = c.strip(o.Key)
+	if o.Shadowed != nil {
+		var sh = make([]Origin, len(o.Shadowed))
+		for i, so := range o.Shadowed {
+			sh[i] = c.stripOrigin(so)
+		}
+		o.Shadowed = sh
+	}
+	return o
+}
+
+// stripSnapshot returns the snapshot sn of the parent store with the keys under the prefix only.
+// Its keys are normalized and resolved from aliases as the parent does, so reading a key
+// from the snapshot gives the same result as reading it from the view.
+func (c *subStore) stripSnapshot(sn *Snapshot) *Snapshot {
+	if sn == nil {
+		return nil
+	}
+	var p = sn.canonical(c.prefix)
+	return &Snapshot{
+		Version:   sn.Version,
+		Hash:      sn.Hash,
+		CreatedAt: sn.CreatedAt,
+		m:         sn.m.sub(p),
+		key: func(k string) string {
+			var ck = sn.canonical(c.key(k))
+			if kk, ok := stripPrefix(ck, p); ok {
+				return kk
+			}
+			// the key is aliased to a key outside of the prefix, no key of the snapshot
+			// starts with a separator so it is not found
+			return KeySep + ck
+		},
+	}
+}
+
+// readOnly logs that the method m was ignored as the store is read only
+func (c *subStore) readOnly(m string) {
+	c.c.cfg.Logger.Get().Warn(m + " on " + c.Name() + " ignored: " + ErrReadOnly.Error())
+}
+
+// detachedLoader returns a ConfigLoader which is not registered in the store,
+// so that calls chained on loaders registered on the view do not panic
+func (c *subStore) detachedLoader(l Loader, w Watcher, loaderHooks LoaderHooks) *ConfigLoader {
+	return c.c.newConfigLoader(&loaderWatcher{
+		Loader:      l,
+		Watcher:     w,
+		s:           c.c,
+		loaderHooks: loaderHooks,
+	})
+}
+
+// Name returns the name of the parent store followed by the prefix
+func (c *subStore) Name() string {
+	return c.c.Name() + KeySep + c.prefix
+}
+
+// SetLogger does nothing as the store is read only
+func (c *subStore) SetLogger(l nlogger.Structured) {
+	c.readOnly("SetLogger")
+}
+
+// RegisterLoader does nothing as the store is read only, the loader returned is not registered
+func (c *subStore) RegisterLoader(l Loader, loaderHooks ...func(Store) error) *ConfigLoader {
+	c.readOnly("RegisterLoader")
+	return c.detachedLoader(l, NopWatcher{}, loaderHooks)
+}
+
+// RegisterLoaderWatcher does nothing as the store is read only, the loader returned is not registered
+func (c *subStore) RegisterLoaderWatcher(lw LoaderWatcher, loaderHooks ...func(Store) error) *ConfigLoader {
+	c.readOnly("RegisterLoaderWatcher")
+	return c.detachedLoader(lw, lw, loaderHooks)
+}
+
+// RegisterCloser does nothing as the store is read only
+func (c *subStore) RegisterCloser(closer io.Closer) Store {
+	c.readOnly("RegisterCloser")
+	return c
+}
+
+// RegisterKeyHook adds a hook to be run when the key k of the view changes, the hook is given the view
+func (c *subStore) RegisterKeyHook(k string, h func(Store) error) Store {
+	c.c.RegisterKeyHook(c.key(k), func(Store) error {
+		return h(c)
+	})
+	return c
+}
+
+// OnChange adds a hook run with the changes of the key p of the view and its subkeys
+func (c *subStore) OnChange(p string, f func(ChangeSet) error) Store {
+	c.c.OnChange(c.key(p), func(cs ChangeSet) error {
+		return f(c.stripChangeSet(cs))
+	})
+	return c
+}
+
+// Subscribe returns a channel receiving an Event after each load changing the key p of the view or its subkeys
+func (c *subStore) Subscribe(p string) (<-chan Event, func()) {
+	var events, cancel = c.c.Subscribe(c.key(p))
+	var ch = make(chan Event, cap(events))
+	var done = make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		defer close(ch)
+		for e := range events {
+			e.ChangeSet = c.stripChangeSet(e.ChangeSet)
+			select {
+			case ch <- e:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return ch, func() {
+		once.Do(func() {
+			close(done)
+			cancel()
+		})
+	}
+}
+
+// Snapshot returns the current snapshot of the view
+func (c *subStore) Snapshot() *Snapshot {
+	return c.stripSnapshot(c.c.Snapshot())
+}
+
+// Version returns the current version of the parent store
+func (c *subStore) Version() uint64 {
+	return c.c.Version()
+}
+
+// History returns the snapshots of the view kept in the history of the parent store
+func (c *subStore) History() []*Snapshot {
+	var h = c.c.History()
+	for i, sn := range h {
+		h[i] = c.stripSnapshot(sn)
+	}
+	return h
+}
+
+// Rollback returns ErrReadOnly as the store is read only
+func (c *subStore) Rollback(version uint64) error {
+	return ErrReadOnly
+}
+
+// View returns a read only view of the current values of the view
+func (c *subStore) View() *Snapshot {
+	return c.stripSnapshot(c.c.View())
+}
+
+// BindPrefix binds a value to the keys under the prefix p of the view. As the store is read only,
+// the binding is not registered in the parent store: the value is rebuilt from the current values when read,
+// the tags of the struct are ignored and decode errors never fail a load.
+// Hooks added to the binding are given the view, as the hooks registered with RegisterKeyHook.
+func (c *subStore) BindPrefix(p string, v interface{}) *Binding {
+	var b = &Binding{
+		s:      c.c,
+		prefix: c.c.canonical(strings.TrimSuffix(c.key(p), KeySep)),
+		v:      c.c.newValue(v, ErrIncorrectBindingValue),
+		view:   c,
+		mut:    &sync.Mutex{},
+	}
+	b.refresh()
+	return b
+}
+
+// Secret does nothing as the store is read only
+func (c *subStore) Secret(keys ...string) Store {
+	c.readOnly("Secret")
+	return c
+}
+
+// IsSecret checks if the key k of the view is secret
+func (c *subStore) IsSecret(k string) bool {
+	return c.c.IsSecret(c.key(k))
+}
+
+// RegisterValidator does nothing as the store is read only
+func (c *subStore) RegisterValidator(v Validator) Store {
+	c.readOnly("RegisterValidator")
+	return c
+}
+
+// RegisterPreCommitHook does nothing as the store is read only
+func (c *subStore) RegisterPreCommitHook(f func(*Snapshot) error) Store {
+	c.readOnly("RegisterPreCommitHook")
+	return c
+}
+
+// Reload returns ErrReadOnly as the store is read only
+func (c *subStore) Reload() error {
+	return ErrReadOnly
+}
+
+// ReloadContext returns ErrReadOnly as the store is read only
+func (c *subStore) ReloadContext(ctx context.Context) error {
+	return ErrReadOnly
+}
+
+// Strict does nothing as the store is read only
+func (c *subStore) Strict(keys ...string) Store {
+	c.readOnly("Strict")
+	return c
+}
+
+// Alias does nothing as the store is read only
+func (c *subStore) Alias(k string, oldKeys ...string) Store {
+	c.readOnly("Alias")
+	return c
+}
+
+// SetMergeStrategy does nothing as the store is read only
+func (c *subStore) SetMergeStrategy(p string, m MergeStrategy) Store {
+	c.readOnly("SetMergeStrategy")
+	return c
+}
+
+// RunHooks returns ErrReadOnly as the store is read only
+func (c *subStore) RunHooks() error {
+	return ErrReadOnly
+}
+
+// Load returns ErrReadOnly as the store is read only
+func (c *subStore) Load() error {
+	return ErrReadOnly
+}
+
+// Watch returns ErrReadOnly as the store is read only
+func (c *subStore) Watch() error {
+	return ErrReadOnly
+}
+
+// LoadWatch returns ErrReadOnly as the store is read only
+func (c *subStore) LoadWatch() error {
+	return ErrReadOnly
+}
+
+// LoadContext returns ErrReadOnly as the store is read only
+func (c *subStore) LoadContext(ctx context.Context) error {
+	return ErrReadOnly
+}
+
+// WatchContext returns ErrReadOnly as the store is read only
+func (c *subStore) WatchContext(ctx context.Context) error {
+	return ErrReadOnly
+}
+
+// LoadWatchContext returns ErrReadOnly as the store is read only
+func (c *subStore) LoadWatchContext(ctx context.Context) error {
+	return ErrReadOnly
+}
+
+// LoadLoader returns ErrReadOnly as the store is read only
+func (c *subStore) LoadLoader(name string) error {
+	return ErrReadOnly
+}
+
+// LoadLoaderContext returns ErrReadOnly as the store is read only
+func (c *subStore) LoadLoaderContext(ctx context.Context, name string) error {
+	return ErrReadOnly
+}
+
+// Close returns ErrReadOnly as the store is read only
+func (c *subStore) Close(ctx context.Context) error {
+	return ErrReadOnly
+}
+
+// Done returns a channel which is closed when the parent store is closed
+func (c *subStore) Done() <-chan struct{} {
+	return c.c.Done()
+}
+
+// Err returns the error which caused the parent store to close
+func (c *subStore) Err() error {
+	return c.c.Err()
+}
+
+// Group returns a live read only view of the keys under the path prefix g of the view
+func (c *subStore) Group(g string) Store {
+	return c.Sub(g)
+}
+
+// Groups returns nil as a view has no groups
+func (c *subStore) Groups() []string {
+	return nil
+}
+
+// Getter returns a GetterTyped for the key k of the view
+func (c *subStore) Getter(k string) ngetter.GetterTyped {
+	return ngetter.GetterTypedFunc(func() interface{} {
+		return c.Get(k)
+	})
+}
+
+// Get gets the value with the key k from the view
+func (c *subStore) Get(k string) interface{} {
+	return c.c.Get(c.key(k))
+}
+
+// MustGet gets the value with the key k from the view and panics if the key does not exist
+func (c *subStore) MustGet(k string) interface{} {
+	return c.c.MustGet(c.key(k))
+}
+
+// Set returns ErrReadOnly as the store is read only
+func (c *subStore) Set(k string, v interface{}) error {
+	return ErrReadOnly
+}
+
+// Delete returns ErrReadOnly as the store is read only
+func (c *subStore) Delete(k string) error {
+	return ErrReadOnly
+}
+
+// Override returns ErrReadOnly as the store is read only
+func (c *subStore) Override(k string, v interface{}, ttl time.Duration) error {
+	return ErrReadOnly
+}
+
+// ClearOverride returns ErrReadOnly as the store is read only
+func (c *subStore) ClearOverride(k string) error {
+	return ErrReadOnly
+}
+
+// Exists checks whether the key k is set in the view
+func (c *subStore) Exists(k string) bool {
+	return c.c.Exists(c.key(k))
+}
+
+// Origin returns the origin of the key k of the view
+func (c *subStore) Origin(k string) (Origin, bool) {
+	var o, ok = c.c.Origin(c.key(k))
+	if !ok {
+		return o, false
+	}
+	return c.stripOrigin(o), true
+}
+
+// Explain returns the origins of all keys in the view sorted by key
+func (c *subStore) Explain() []Origin {
+	var res = make([]Origin, 0)
+	for _, o := range c.c.Explain() {
+		if _, ok := c.stripKey(o.Key); ok {
+			res = append(res, c.stripOrigin(o))
+		}
+	}
+	return res
+}
+
+// Health returns the health of the store of the view
+func (c *subStore) Health() StoreHealth {
+	return c.c.Health()
+}
+
+// Keys returns the sorted keys of the view with the path prefix p
+func (c *subStore) Keys(p string) []string {
+	var keys = make([]string, 0)
+	for _, k := range c.c.Keys(c.key(p)) {
+		if kk, ok := c.stripKey(k); ok {
+			keys = append(keys, kk)
+		}
+	}
+	return keys
+}
+
+// Tree returns the values of the keys under the path prefix p of the view as nested maps
+func (c *subStore) Tree(p string) map[string]interface{} {
+	return c.c.Tree(c.key(p))
+}
+
+// Sub returns a live read only view of the keys under the path prefix p of the view
+func (c *subStore) Sub(p string) Store {
+	return c.c.Sub(c.key(p))
+}
+
+// Slice rebuilds the elements of the array with the key p of the view from its indexed keys
+func (c *subStore) Slice(p string) []map[string]interface{} {
+	return c.c.Slice(c.key(p))
+}
+
+// MustString gets the value with the key k from the view and casts it to a string, it panics if the key does not exist
+func (c *subStore) MustString(k string) string {
+	return c.c.MustString(c.key(k))
+}
+
+// String gets the value with the key k from the view and casts it to a string
+func (c *subStore) String(k string) string {
+	return c.c.String(c.key(k))
+}
+
+// MustInt gets the value with the key k from the view and casts it to an int, it panics if the key does not exist
+func (c *subStore) MustInt(k string) int {
+	return c.c.MustInt(c.key(k))
+}
+
+// Int gets the value with the key k from the view and casts it to an int
+func (c *subStore) Int(k string) int {
+	return c.c.Int(c.key(k))
+}
+
+// MustFloat gets the value with the key k from the view and casts it to a float64, it panics if the key does not exist
+func (c *subStore) MustFloat(k string) float64 {
+	return c.c.MustFloat(c.key(k))
+}
+
+// Float gets the value with the key k from the view and casts it to a float64
+func (c *subStore) Float(k string) float64 {
+	return c.c.Float(c.key(k))
+}
+
+// MustBool gets the value with the key k from the view and casts it to a bool, it panics if the key does not exist
+func (c *subStore) MustBool(k string) bool {
+	return c.c.MustBool(c.key(k))
+}
+
+// Bool gets the value with the key k from the view and casts it to a bool
+func (c *subStore) Bool(k string) bool {
+	return c.c.Bool(c.key(k))
+}
+
+// MustDuration gets the value with the key k from the view and casts it to a time.Duration, it panics if the key does not exist
+func (c *subStore) MustDuration(k string) time.Duration {
+	return c.c.MustDuration(c.key(k))
+}
+
+// Duration gets the value with the key k from the view and casts it to a time.Duration
+func (c *subStore) Duration(k string) time.Duration {
+	return c.c.Duration(c.key(k))
+}
+
+// MustTime gets the value with the key k from the view and casts it to a time.Time, it panics if the key does not exist
+func (c *subStore) MustTime(k string) time.Time {
+	return c.c.MustTime(c.key(k))
+}
+
+// Time gets the value with the key k from the view and casts it to a time.Time
+func (c *subStore) Time(k string) time.Time {
+	return c.c.Time(c.key(k))
+}
+
+// MustStringSlice gets the value with the key k from the view and casts it to a []string, it panics if the key does not exist
+func (c *subStore) MustStringSlice(k string) []string {
+	return c.c.MustStringSlice(c.key(k))
+}
+
+// StringSlice gets the value with the key k from the view and casts it to a []string
+func (c *subStore) StringSlice(k string) []string {
+	return c.c.StringSlice(c.key(k))
+}
+
+// MustIntSlice gets the value with the key k from the view and casts it to a []int, it panics if the key does not exist
+func (c *subStore) MustIntSlice(k string) []int {
+	return c.c.MustIntSlice(c.key(k))
+}
+
+// IntSlice gets the value with the key k from the view and casts it to a []int
+func (c *subStore) IntSlice(k string) []int {
+	return c.c.IntSlice(c.key(k))
+}
+
+// MustStringMap gets the value with the key k from the view and casts it to a map[string]interface{}, it panics if the key does not exist
+func (c *subStore) MustStringMap(k string) map[string]interface{} {
+	return c.c.MustStringMap(c.key(k))
+}
+
+// StringMap gets the value with the key k from the view and casts it to a map[string]interface{}
+func (c *subStore) StringMap(k string) map[string]interface{} {
+	return c.c.StringMap(c.key(k))
+}
+
+// MustStringMapString gets the value with the key k from the view and casts it to a map[string]string, it panics if the key does not exist
+func (c *subStore) MustStringMapString(k string) map[string]string {
+	return c.c.MustStringMapString(c.key(k))
+}
+
+// StringMapString gets the value with the key k from the view and casts it to a map[string]string
+func (c *subStore) StringMapString(k string) map[string]string {
+	return c.c.StringMapString(c.key(k))
+}
+
+// Bind does nothing as the store is read only, use BindPrefix to bind a value to the keys of the view
+func (c *subStore) Bind(v interface{}) {
+	c.readOnly("Bind")
+}
+
+// BindStructStrict does nothing as the store is read only
+func (c *subStore) BindStructStrict(v interface{}) {
+	c.readOnly("BindStructStrict")
+}
+
+// Value returns nil as no value can be bound to a read only store
+func (c *subStore) Value() interface{} {
+	return nil
+}
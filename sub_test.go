@@ -0,0 +1,186 @@
+package konfig
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSub(t *testing.T) {
+	t.Run(
+		"getters",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.Set("db.host", "localhost")
+			c.Set("db.timeout", "1s")
+			c.Set("db.pool.size", 10)
+			c.Set("port", 8080)
+
+			var db = c.Sub("db")
+			require.Equal(t, "localhost", db.MustString("host"))
+			require.Equal(t, time.Second, db.Duration("timeout"))
+			require.False(t, db.Exists("port"))
+			require.Equal(t, []string{"host", "pool.size", "timeout"}, db.Keys(""))
+			require.Equal(t, map[string]interface{}{"size": 10}, db.Tree("pool"))
+			require.Equal(t, 10, db.Sub("pool").Int("size"))
+			require.Equal(t, map[string]interface{}{"host": "localhost", "timeout": "1s", "pool.size": 10}, db.View().Values())
+
+			var o, ok = db.Origin("host")
+			require.True(t, ok)
+			require.Equal(t, "host", o.Key)
+
+			// the view is live
+			c.Set("db.host", "remote")
+			require.Equal(t, "remote", db.String("host"))
+		},
+	)
+
+	t.Run(
+		"view keys",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.KeyNormalizer = func(k string) string {
+				return strings.ToLower(strings.ReplaceAll(k, "_", KeySep))
+			}
+			var c = New(cfg)
+			c.Alias("db.host", "db.hostname")
+			var l = &valuesLoader{values: Values{"db.host": "localhost"}}
+			c.RegisterLoader(l)
+			require.Nil(t, c.Load())
+
+			// the view reads keys as the store does
+			var db = c.Sub("DB")
+			var v = db.View()
+			for _, k := range []string{"host", "HOST", "hostname"} {
+				require.Equal(t, db.Get(k), v.Get(k), k)
+				require.Equal(t, "localhost", v.Get(k), k)
+			}
+			require.Equal(t, "localhost", c.Sub("").View().Get("DB_HOST"))
+			require.Equal(t, map[string]interface{}{"host": "localhost"}, v.Values())
+		},
+	)
+
+	t.Run(
+		"hooks",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &valuesLoader{values: Values{"db.host": "localhost"}}
+			var cl = c.RegisterLoader(l)
+			require.Nil(t, c.Load())
+
+			var db = c.Sub("db")
+			var host string
+			db.RegisterKeyHook("host", func(s Store) error {
+				host = s.String("host")
+				return nil
+			})
+			var changes []ChangeSet
+			db.OnChange("", func(cs ChangeSet) error {
+				changes = append(changes, cs)
+				return nil
+			})
+
+			l.values = Values{"db.host": "remote"}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			require.Equal(t, "remote", host)
+			require.Len(t, changes, 1)
+			require.Equal(t, []Change{{Key: "host", Old: "localhost", New: "remote"}}, changes[0].Updated)
+		},
+	)
+
+	t.Run(
+		"read only",
+		func(t *testing.T) {
+			var db = New(DefaultConfig()).Sub("db")
+			require.Equal(t, ErrReadOnly, db.Load())
+			require.Equal(t, ErrReadOnly, db.Set("host", "localhost"))
+			require.NotPanics(t, func() {
+				db.Secret("password").Strict("host")
+				db.RegisterLoader(&valuesLoader{}).WithPriority(1)
+			})
+			require.False(t, db.Exists("host"))
+			require.False(t, db.IsSecret("password"))
+		},
+	)
+
+	t.Run(
+		"key normalizer",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.KeyNormalizer = NormalizeKey
+			var c = New(cfg)
+			c.RegisterLoader(&valuesLoader{values: Values{"DB_HOST": "localhost", "DB_PORT": 5432}})
+			require.Nil(t, c.Load())
+
+			var changes []ChangeSet
+			var db = c.Sub("DB")
+			db.OnChange("", func(cs ChangeSet) error {
+				changes = append(changes, cs)
+				return nil
+			})
+
+			require.Equal(t, "localhost", db.Get("host"))
+			require.Equal(t, []string{"host", "port"}, db.Keys(""))
+			var origins = db.Explain()
+			require.Len(t, origins, 2)
+			require.Equal(t, "host", origins[0].Key)
+
+			c.Set("db-host", "remote")
+			require.Len(t, changes, 1)
+			require.Equal(t, []string{"host"}, changes[0].Keys())
+		},
+	)
+
+	t.Run(
+		"bind prefix",
+		func(t *testing.T) {
+			type Pool struct {
+				Size int `konfig:"size" default:"5"`
+			}
+			var c = New(DefaultConfig())
+			c.Set("db.pool.size", 10)
+
+			// the binding is not registered on the parent store
+			var b = c.Sub("db").BindPrefix("pool", Pool{})
+			require.Equal(t, Pool{Size: 10}, b.Value())
+			require.Empty(t, c.bindings)
+
+			// the value is live and hooks are given the view
+			var ran Store
+			b.AddHooks(func(s Store) error {
+				ran = s
+				return nil
+			})
+			c.Set("db.pool.size", 20)
+			require.Equal(t, Pool{Size: 20}, b.Value())
+			require.Equal(t, "root.db", ran.Name())
+
+			// once unbound the value and the hooks are not updated anymore
+			b.Unbind()
+			ran = nil
+			c.Set("db.pool.size", 30)
+			require.Equal(t, Pool{Size: 20}, b.Value())
+			require.Nil(t, ran)
+			require.Empty(t, c.changeHooks)
+
+			// defaults are not set on the parent store
+			c.Sub("db").BindPrefix("other", Pool{})
+			require.False(t, c.Exists("db.other.size"))
+		},
+	)
+
+	t.Run(
+		"group",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.Set("db.pool.size", 10)
+
+			var pool = c.Sub("db").Group("pool")
+			require.Equal(t, 10, pool.Int("size"))
+			require.Equal(t, []string{"size"}, pool.Keys(""))
+		},
+	)
+}
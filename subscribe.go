@@ -0,0 +1,133 @@
+package konfig
+
+import "time"
+
+const defaultSubscribeBuffer = 16
+
+// DropPolicy is the policy applied when the buffer of a subscriber is full
+type DropPolicy int
+
+const (
+	// DropNewest drops the new event when the buffer of a subscriber is full. It is the default policy.
+	DropNewest DropPolicy = iota
+	// DropOldest drops the oldest buffered event to make room for the new event.
+	DropOldest
+)
+
+// Event is the event sent to subscribers when keys under their prefix change
+type Event struct {
+	// ChangeSet is the list of changes under the prefix of the subscription
+	ChangeSet
+	// Time is the time at which the changes were applied
+	Time time.Time
+	// Dropped is the number of events dropped for the subscriber since the last event it received
+	Dropped int
+}
+
+type subscription struct {
+	prefix  string
+	ch      chan Event
+	dropped int
+}
+
+// send sends the event without ever blocking, applying the drop policy if the buffer is full
+func (sub *subscription) send(e Event, p DropPolicy) {
+	e.Dropped = sub.dropped
+	select {
+	case sub.ch <- e:
+		sub.dropped = 0
+		return
+	default:
+	}
+
+	if p == DropOldest {
+		select {
+		case <-sub.ch:
+			sub.dropped++
+		default:
+		}
+		e.Dropped = sub.dropped
+		select {
+		case sub.ch <- e:
+			sub.dropped = 0
+			return
+		default:
+		}
+	}
+
+	sub.dropped++
+}
+
+// Subscribe subscribes to the changes of the key p and all subkeys of p in the global store
+func Subscribe(p string) (<-chan Event, func()) {
+	return instance().Subscribe(p)
+}
+
+// Subscribe returns a channel receiving an Event after each load changing the key p or a subkey of p,
+// and a function to cancel the subscription. Events are sent without blocking, when the buffer is full
+// events are dropped following Config.SubscribeDropPolicy, so subscribers which don't keep up miss events.
+// The channel is closed when the subscription is cancelled or when the store is closed.
+func (c *S) Subscribe(p string) (<-chan Event, func()) {
+	p = c.canonical(p)
+	var size = c.cfg.SubscribeBuffer
+	if size <= 0 {
+		size = defaultSubscribeBuffer
+	}
+	var sub = &subscription{
+		prefix: p,
+		ch:     make(chan Event, size),
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	select {
+	case <-c.quit:
+		// the store is closed, we return a closed channel
+		close(sub.ch)
+		return sub.ch, func() {}
+	default:
+	}
+
+	if c.subs == nil {
+		c.subs = make(map[*subscription]struct{})
+	}
+	c.subs[sub] = struct{}{}
+
+	return sub.ch, func() {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+
+		if _, ok := c.subs[sub]; ok {
+			delete(c.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// publish sends the changes to the matching subscribers, it must be called with c.mut locked.
+// The loader name is resolved only if a subscriber matches and is kept in cs.
+func (c *S) publish(cs *ChangeSet, wl *loaderWatcher, t time.Time) {
+	for sub := range c.subs {
+		var pcs = cs.Prefix(sub.prefix)
+		if pcs.Len() == 0 {
+			continue
+		}
+		if wl != nil && cs.Loader == "" {
+			cs.Loader = wl.Name()
+		}
+		pcs.Loader = cs.Loader
+		sub.send(Event{ChangeSet: pcs, Time: t}, c.cfg.SubscribeDropPolicy)
+	}
+}
+
+// closeSubs closes the channels of all subscribers
+func (c *S) closeSubs() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	for sub := range c.subs {
+		close(sub.ch)
+	}
+	c.subs = nil
+}
@@ -0,0 +1,129 @@
+package konfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe(t *testing.T) {
+	t.Run(
+		"receives changes under prefix",
+		func(t *testing.T) {
+			// the default buffer keeps the event until we receive it
+			var c = New(DefaultConfig())
+			c.RegisterLoader(&valuesLoader{values: Values{"db.host": "localhost", "foo": "bar"}})
+
+			var ch, cancel = c.Subscribe("db")
+			defer cancel()
+
+			require.Nil(t, c.Load())
+
+			var e = <-ch
+			require.Equal(t, "dummy", e.Loader)
+			require.Equal(t, []Change{{Key: "db.host", New: "localhost"}}, e.Added)
+			require.Equal(t, 0, e.Dropped)
+			require.False(t, e.Time.IsZero())
+		},
+	)
+
+	t.Run(
+		"drop newest",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.SubscribeBuffer = 1
+			var c = New(cfg)
+			var l = &valuesLoader{values: Values{"foo": 1}}
+			var cl = c.RegisterLoader(l)
+
+			var ch, cancel = c.Subscribe("")
+			defer cancel()
+
+			require.Nil(t, c.Load())
+			for i := 2; i <= 3; i++ {
+				l.values = Values{"foo": i}
+				require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			}
+
+			var e = <-ch
+			require.Equal(t, []Change{{Key: "foo", New: 1}}, e.Added)
+
+			l.values = Values{"foo": 4}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+			e = <-ch
+			require.Equal(t, []Change{{Key: "foo", Old: 3, New: 4}}, e.Updated)
+			require.Equal(t, 2, e.Dropped)
+		},
+	)
+
+	t.Run(
+		"drop oldest",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.SubscribeBuffer = 1
+			cfg.SubscribeDropPolicy = DropOldest
+			var c = New(cfg)
+			var l = &valuesLoader{values: Values{"foo": 1}}
+			var cl = c.RegisterLoader(l)
+
+			var ch, cancel = c.Subscribe("")
+			defer cancel()
+
+			require.Nil(t, c.Load())
+			l.values = Values{"foo": 2}
+			require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+
+			var e = <-ch
+			require.Equal(t, []Change{{Key: "foo", Old: 1, New: 2}}, e.Updated)
+			require.Equal(t, 1, e.Dropped)
+		},
+	)
+
+	t.Run(
+		"values set with Set are published",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+
+			var changes []ChangeSet
+			c.OnChange("foo", func(cs ChangeSet) error {
+				changes = append(changes, cs)
+				return nil
+			})
+
+			var ch, cancel = c.Subscribe("foo")
+			defer cancel()
+
+			c.Set("foo", "bar")
+
+			var e = <-ch
+			require.Equal(t, OriginSet, e.Loader)
+			require.Equal(t, []Change{{Key: "foo", New: "bar"}}, e.Added)
+			require.Len(t, changes, 1)
+			require.Equal(t, []Change{{Key: "foo", New: "bar"}}, changes[0].Added)
+			require.Equal(t, uint64(1), c.Version())
+		},
+	)
+
+	t.Run(
+		"cancel and close",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+
+			var ch, cancel = c.Subscribe("")
+			cancel()
+			cancel()
+			var _, ok = <-ch
+			require.False(t, ok)
+
+			ch, _ = c.Subscribe("")
+			require.Nil(t, c.Close(context.Background()))
+			_, ok = <-ch
+			require.False(t, ok)
+
+			ch, _ = c.Subscribe("")
+			_, ok = <-ch
+			require.False(t, ok)
+		},
+	)
+}
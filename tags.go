@@ -0,0 +1,199 @@
+package konfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	// TagDefault is the tag key for the default value of a bound field, it seeds the store if the key is not set
+	TagDefault = "default"
+	// TagValidate is the tag key for the validation rules of a bound field, for example `validate:"min=1,max=65535"`.
+	// Supported rules are min=N, max=N, oneof=a b c and regexp=expr which must be the last rule.
+	TagValidate = "validate"
+	// TagRequired is the tag key to opt a bound field out of strict keys with `required:"false"`
+	TagRequired = "required"
+	// TagSecret is the tag key to mark a bound field as secret with `secret:"true"`
+	TagSecret = "secret"
+)
+
+// SecretMask is the value displayed in place of secret values
+const SecretMask = "******"
+
+type structField struct {
+	key   string
+	field reflect.StructField
+}
+
+// structFields returns the leaf fields of the struct type t with their config keys
+func structFields(t reflect.Type, prefix string) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		var fieldValue = t.Field(i)
+		var tag = fieldValue.Tag.Get(TagKey)
+
+		if tag == "-" {
+			continue
+		}
+
+		// use field name when konfig tag is not specified
+		if tag == "" {
+			tag = strings.ToLower(fieldValue.Name)
+		}
+
+		if fieldValue.Type.Kind() == reflect.Struct && !isLeaf(fieldValue.Type) {
+			// don't add the parent tag
+			fields = append(fields, structFields(fieldValue.Type, prefix+tag+KeySep)...)
+			continue
+		}
+
+		fields = append(fields, structField{key: prefix + tag, field: fieldValue})
+	}
+	return fields
+}
+
+// bindTags seeds the store with the default values of the struct type t bound to the key prefix,
+// marks secret fields and returns the schema of the validate tags or nil if there is none.
+func (c *S) bindTags(t reflect.Type, prefix string) *Schema {
+	var sc *Schema
+	for _, f := range structFields(t, prefix) {
+		if d, ok := f.field.Tag.Lookup(TagDefault); ok && !c.Exists(f.key) {
+			c.Set(f.key, d)
+		}
+
+		var secret = f.field.Tag.Get(TagSecret) == "true"
+		if secret {
+			c.Secret(f.key)
+		}
+
+		if tag, ok := f.field.Tag.Lookup(TagValidate); ok {
+			if sc == nil {
+				sc = NewSchema()
+			}
+			var r = sc.Key(f.key)
+			if secret {
+				r.Secret()
+			}
+			if err := r.parseTag(tag); err != nil {
+				panic(fmt.Errorf("invalid validate tag on field %s: %v", f.field.Name, err))
+			}
+		}
+	}
+	return sc
+}
+
+// parseTag adds the rules of a validate tag to the rule
+func (r *Rule) parseTag(tag string) error {
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "regexp=") {
+			// the regexp takes the rest of the tag
+			rule, tag = tag, ""
+		} else {
+			var parts = strings.SplitN(tag, ",", 2)
+			rule, tag = parts[0], ""
+			if len(parts) == 2 {
+				tag = parts[1]
+			}
+		}
+
+		var kv = strings.SplitN(rule, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid rule %q", rule)
+		}
+
+		switch kv[0] {
+		case "min":
+			var min, err = cast.ToFloat64E(kv[1])
+			if err != nil {
+				return err
+			}
+			r.Min(min)
+		case "max":
+			var max, err = cast.ToFloat64E(kv[1])
+			if err != nil {
+				return err
+			}
+			r.Max(max)
+		case "oneof":
+			var values = strings.Fields(kv[1])
+			var enum = make([]interface{}, len(values))
+			for i, v := range values {
+				enum[i] = v
+			}
+			r.Enum(enum...)
+		case "regexp":
+			r.Pattern(kv[1])
+		default:
+			return fmt.Errorf("unknown rule %q", kv[0])
+		}
+	}
+	return nil
+}
+
+// Secret marks keys of the global store as secret
+func Secret(keys ...string) Store {
+	return instance().Secret(keys...)
+}
+
+// Secret marks keys as secret, their values and the values of their subkeys should never be displayed
+func (c *S) Secret(keys ...string) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.secrets == nil {
+		c.secrets = make(map[string]struct{})
+	}
+	for _, k := range keys {
+		c.secrets[k] = struct{}{}
+	}
+	return c
+}
+
+// IsSecret checks if the key k of the global store is secret
+func IsSecret(k string) bool {
+	return instance().IsSecret(k)
+}
+
+// IsSecret checks if the key k is secret: it is a secret key or under the path of one,
+// or with Interpolation its value references a secret key, an env var or a file. Keys are compared once normalized and with aliases resolved.
+func (c *S) IsSecret(k string) bool {
+	k = c.canonical(k)
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	return c.isSecret(k, c.raw, make(map[string]struct{}))
+}
+
+// isSecret checks if the key k is secret, raw holds the values before interpolation
+// and visited the keys already checked
+func (c *S) isSecret(k string, raw s, visited map[string]struct{}) bool {
+	if _, ok := visited[k]; ok {
+		return false
+	}
+	visited[k] = struct{}{}
+
+	for sk := range c.secrets {
+		if hasKeyPrefix(k, c.canonical(sk)) {
+			return true
+		}
+	}
+
+	// a value resolved from a secret key, an env var or a file is secret as well
+	if !c.cfg.Interpolation {
+		return false
+	}
+	var refs, external = keyRefs(raw[k])
+	if external {
+		return true
+	}
+	for _, ref := range refs {
+		if c.isSecret(c.canonical(ref), raw, visited) {
+			return true
+		}
+	}
+	return false
+}
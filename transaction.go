@@ -0,0 +1,154 @@
+package konfig
+
+import (
+	"context"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// OriginReload is the loader name set in the ChangeSet of a transactional reload
+const OriginReload = "reload"
+
+// PreCommitHooks are functions ran with the new values of the store before they are committed
+type PreCommitHooks []func(*Snapshot) error
+
+func (h PreCommitHooks) run(sn *Snapshot) error {
+	for _, f := range h {
+		if err := f(sn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterPreCommitHook adds a hook to the global store run before new values are committed
+func RegisterPreCommitHook(f func(*Snapshot) error) Store {
+	return instance().RegisterPreCommitHook(f)
+}
+
+// RegisterPreCommitHook adds a hook run with the new values of the store before they are committed.
+// If the hook returns an error, the new values are discarded and the load is considered a failure.
+// Hooks must not modify the store.
+func (c *S) RegisterPreCommitHook(f func(*Snapshot) error) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.preCommitHooks = append(c.preCommitHooks, f)
+	return c
+}
+
+// Reload reloads all loaders of the global store in a single transaction
+func Reload() error {
+	return instance().Reload()
+}
+
+// Reload reloads all loaders in a single transaction
+func (c *S) Reload() error {
+	return c.ReloadContext(context.Background())
+}
+
+// ReloadContext reloads all loaders of the global store in a single transaction with the given context
+func ReloadContext(ctx context.Context) error {
+	return instance().ReloadContext(ctx)
+}
+
+// ReloadContext loads all loaders into a staging area, checks strict keys, runs the pre-commit hooks
+// and only then replaces the values of the store. If any loader or check fails, the previous values are kept
+// and ReloadContext returns an error listing all failures. If a loader fails or if the checks or hooks fail,
+// the store is stopped when a failing loader says it should stop on failure.
+func (c *S) ReloadContext(ctx context.Context) error {
+	if len(c.WatcherLoaders) == 0 {
+		panic(ErrNoLoaders)
+	}
+
+	ctx, cancel := c.withQuit(ctx)
+	defer cancel()
+
+	// once the store has been loaded, every load is checked before it is committed, even if this one fails
+	defer c.setLoaded()
+
+	var multiErr error
+	var stop bool
+	var staged = make(map[*loaderWatcher]Values, len(c.WatcherLoaders))
+	var values, errs = c.fetchAll(ctx)
+	for i, wl := range c.WatcherLoaders {
+		if errs[i] != nil {
+			c.recordLoad(ctx, wl, errs[i])
+			multiErr = multierror.Append(multiErr, errs[i])
+			stop = stop || wl.StopOnFailure()
+			continue
+		}
+		staged[wl] = values[i].values
+	}
+
+	if multiErr != nil {
+		// if a loader says we should stop in failure, stop the world,
+		// unless ctx is done and the reload has been aborted
+		if stop && ctx.Err() == nil {
+			c.stop(multiErr)
+		}
+		return multiErr
+	}
+
+	var cs, err = c.apply(staged, nil, true)
+	for i, wl := range c.WatcherLoaders {
+		if err == nil {
+			c.committed(wl, values[i])
+		}
+		c.recordLoad(ctx, wl, err)
+	}
+	if err == nil {
+		err = c.runReloadHooks(cs)
+	}
+
+	// the checks or the hooks failed for all loaders,
+	// if one of them says we should stop in failure, stop the world
+	if err != nil {
+		for _, wl := range c.WatcherLoaders {
+			if wl.StopOnFailure() {
+				c.stop(err)
+				break
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// runReloadHooks runs the change hooks and key hooks with the changes cs of a reload, then the hooks of all loaders
+func (c *S) runReloadHooks(cs ChangeSet) error {
+	// run change hooks
+	c.mut.Lock()
+	var changeHooks = c.changeHooks
+	c.mut.Unlock()
+	if err := changeHooks.run(cs, nil); err != nil {
+		c.cfg.Logger.Get().Error("Error while running change hooks: " + err.Error())
+		return err
+	}
+
+	// run key hooks
+	if cs.Len() != 0 && c.keyHooks != nil {
+		if err := c.keyHooks.runForKeys(cs.Keys(), c); err != nil {
+			return err
+		}
+	}
+
+	// run the loader hooks, as for a load they are not run with c.mut locked
+	c.mut.Lock()
+	var wls = c.WatcherLoaders
+	c.mut.Unlock()
+
+	c.hooksMut.Lock()
+	defer c.hooksMut.Unlock()
+	for _, wl := range wls {
+		if wl.loaderHooks != nil {
+			if err := wl.loaderHooks.Run(c); err != nil {
+				c.cfg.Logger.Get().Error("Error while running loader hooks: " + err.Error())
+				return err
+			}
+		}
+	}
+
+	return nil
+}
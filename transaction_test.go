@@ -0,0 +1,217 @@
+package konfig
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/require"
+)
+
+type failingLoader struct {
+	valuesLoader
+	fail bool
+}
+
+func (l *failingLoader) Load(v Values) error {
+	if l.fail {
+		return errors.New("failed")
+	}
+	return l.valuesLoader.Load(v)
+}
+
+type countingLoader struct {
+	valuesLoader
+	loads int32
+}
+
+func (l *countingLoader) Load(v Values) error {
+	atomic.AddInt32(&l.loads, 1)
+	return l.valuesLoader.Load(v)
+}
+
+type chanWatcher struct {
+	events chan struct{}
+	done   chan struct{}
+}
+
+func newChanWatcher() *chanWatcher {
+	return &chanWatcher{events: make(chan struct{}), done: make(chan struct{})}
+}
+
+func (w *chanWatcher) Start() error           { return nil }
+func (w *chanWatcher) Done() <-chan struct{}  { return w.done }
+func (w *chanWatcher) Watch() <-chan struct{} { return w.events }
+func (w *chanWatcher) Err() error             { return nil }
+func (w *chanWatcher) Close() error {
+	close(w.done)
+	return nil
+}
+
+func TestReload(t *testing.T) {
+	t.Run(
+		"all loaders succeed",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var ranHook bool
+			c.RegisterLoader(&valuesLoader{values: Values{"foo": "bar"}}, func(Store) error {
+				ranHook = true
+				return nil
+			})
+			c.RegisterLoader(&valuesLoader{values: Values{"bar": "baz"}})
+
+			var changes []ChangeSet
+			c.OnChange("", func(cs ChangeSet) error {
+				changes = append(changes, cs)
+				return nil
+			})
+
+			require.Nil(t, c.Reload())
+			require.Equal(t, "bar", c.String("foo"))
+			require.Equal(t, "baz", c.String("bar"))
+			require.Equal(t, uint64(1), c.Version())
+			require.True(t, ranHook)
+			require.Len(t, changes, 1)
+			require.Equal(t, OriginReload, changes[0].Loader)
+		},
+	)
+
+	t.Run(
+		"a loader fails",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var first = &failingLoader{valuesLoader: valuesLoader{values: Values{"foo": "bar"}}}
+			var second = &failingLoader{valuesLoader: valuesLoader{values: Values{"bar": "baz"}}}
+			var third = &failingLoader{valuesLoader: valuesLoader{values: Values{"baz": "foo"}}}
+			c.RegisterLoader(first)
+			c.RegisterLoader(second)
+			c.RegisterLoader(third)
+
+			require.Nil(t, c.Reload())
+
+			first.values = Values{"foo": "new"}
+			second.fail = true
+			third.fail = true
+
+			var err = c.Reload()
+			require.NotNil(t, err)
+			require.Len(t, err.(*multierror.Error).Errors, 2)
+
+			// previous state is kept
+			require.Equal(t, "bar", c.String("foo"))
+			require.Equal(t, uint64(1), c.Version())
+		},
+	)
+
+	t.Run(
+		"strict keys fail",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &valuesLoader{values: Values{"foo": "bar"}}
+			c.RegisterLoader(l)
+			c.Strict("foo")
+
+			require.Nil(t, c.Reload())
+
+			l.values = Values{"bar": "baz"}
+			require.NotNil(t, c.Reload())
+			require.Equal(t, "bar", c.String("foo"))
+			require.False(t, c.Exists("bar"))
+		},
+	)
+
+	t.Run(
+		"pre-commit hook vetoes",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			var l = &valuesLoader{values: Values{"port": 8080}}
+			c.RegisterLoader(l)
+			c.RegisterPreCommitHook(func(sn *Snapshot) error {
+				if sn.Int("port") == 0 {
+					return errors.New("invalid port")
+				}
+				return nil
+			})
+
+			require.Nil(t, c.Reload())
+
+			l.values = Values{"port": 0}
+			require.NotNil(t, c.Reload())
+			require.Equal(t, 8080, c.Int("port"))
+			require.Equal(t, uint64(1), c.Version())
+
+			// the veto also applies to single loader loads
+			require.NotNil(t, c.Load())
+			require.Equal(t, 8080, c.Int("port"))
+		},
+	)
+
+	t.Run(
+		"transactional load",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.Transactional = true
+			var c = New(cfg)
+			c.RegisterLoader(&valuesLoader{values: Values{"foo": "bar"}})
+			var l = &failingLoader{valuesLoader: valuesLoader{values: Values{"bar": "baz"}}, fail: true}
+			c.RegisterLoader(l)
+
+			require.NotNil(t, c.Load())
+			require.False(t, c.Exists("foo"))
+
+			l.fail = false
+			require.Nil(t, c.Load())
+			require.Equal(t, "bar", c.String("foo"))
+			require.Equal(t, "baz", c.String("bar"))
+		},
+	)
+	t.Run(
+		"transactional watcher reloads the loader which changed",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			cfg.Transactional = true
+			var c = New(cfg)
+			var first = &countingLoader{valuesLoader: valuesLoader{values: Values{"foo": "bar"}}}
+			var second = &countingLoader{valuesLoader: valuesLoader{values: Values{"bar": "baz"}}}
+			var w = newChanWatcher()
+			c.RegisterLoaderWatcher(NewLoaderWatcher(first, w))
+			c.RegisterLoader(second)
+
+			require.Nil(t, c.Load())
+			require.Nil(t, c.Watch())
+			defer c.Close(context.Background())
+
+			first.values = Values{"foo": "new"}
+			w.events <- struct{}{}
+			require.Eventually(t, func() bool { return c.String("foo") == "new" }, time.Second, time.Millisecond)
+			require.Equal(t, int32(2), atomic.LoadInt32(&first.loads))
+			require.Equal(t, int32(1), atomic.LoadInt32(&second.loads))
+		},
+	)
+
+	t.Run(
+		"stop on failure of checks",
+		func(t *testing.T) {
+			var cfg = DefaultConfig()
+			var stopErr error
+			cfg.OnFatal = func(err error) {
+				stopErr = err
+			}
+			var c = New(cfg)
+			c.RegisterLoader(&DummyLoader{
+				DataToLoad:    [][2]string{{"foo", "bar"}},
+				stopOnFailure: true,
+			})
+			c.RegisterPreCommitHook(func(sn *Snapshot) error {
+				return errors.New("veto")
+			})
+
+			require.NotNil(t, c.Reload())
+			require.NotNil(t, stopErr)
+			<-c.Done()
+		},
+	)
+}
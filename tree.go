@@ -0,0 +1,134 @@
+package konfig
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Slice rebuilds the elements of the array with the key p of the global store from its indexed keys
+// (p.0.foo, p.1.foo...). The elements are returned in order of index, missing indexes are skipped.
+// If no indexed key exists it returns nil.
+func Slice(p string) []map[string]interface{} {
+	return instance().Slice(p)
+}
+
+// Slice rebuilds the elements of the array with the key p from its indexed keys (p.0.foo, p.1.foo...).
+// The elements are returned in order of index and missing indexes are skipped, so the position
+// of an element in the result is not its index when the indexes are sparse and a large index
+// does not allocate a large slice. If no indexed key exists it returns nil.
+func (c *S) Slice(p string) []map[string]interface{} {
+	p = c.canonical(p)
+	var m = c.m.Load().(s)
+	var elems = make(map[int]map[string]interface{})
+
+	p += KeySep
+	for k, v := range m {
+		if !strings.HasPrefix(k, p) {
+			continue
+		}
+		// the key must be an index followed by a subkey,
+		// scalar elements (p.0) cannot be represented as maps
+		var ks = strings.SplitN(k[len(p):], KeySep, 2)
+		var i, ok = index(ks[0])
+		if !ok || len(ks) != 2 || ks[1] == "" {
+			continue
+		}
+
+		if _, ok := elems[i]; !ok {
+			elems[i] = make(map[string]interface{})
+		}
+		elems[i][ks[1]] = v
+	}
+
+	if len(elems) == 0 {
+		return nil
+	}
+
+	var idx = make([]int, 0, len(elems))
+	for i := range elems {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+
+	var l = make([]map[string]interface{}, len(idx))
+	for j, i := range idx {
+		l[j] = unflatten(elems[i])
+	}
+	return l
+}
+
+// Keys returns the sorted keys of the global store with the path prefix p
+func Keys(p string) []string {
+	return instance().Keys(p)
+}
+
+// Keys returns the sorted keys of the store with the path prefix p
+func (c *S) Keys(p string) []string {
+	p = c.canonical(p)
+	var m = c.m.Load().(s)
+	var keys = make([]string, 0)
+	for k := range m {
+		if hasKeyPrefix(k, p) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Tree returns the values of the keys under the path prefix p of the global store as nested maps,
+// the prefix is stripped from the keys.
+func Tree(p string) map[string]interface{} {
+	return instance().Tree(p)
+}
+
+// Tree returns the values of the keys under the path prefix p as nested maps,
+// the prefix is stripped from the keys.
+func (c *S) Tree(p string) map[string]interface{} {
+	return unflatten(c.m.Load().(s).sub(c.canonical(p)))
+}
+
+// unflatten builds nested maps from the flat keys of m.
+// When a key holds a value, its subkeys are ignored, so that an array and its indexed keys
+// are rebuilt as the array only.
+func unflatten(m map[string]interface{}) map[string]interface{} {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	// keys are sorted so that a key always comes before its subkeys
+	sort.Strings(keys)
+
+	var r = make(map[string]interface{})
+	var leaves = make(map[string]bool, len(keys))
+keys:
+	for _, k := range keys {
+		var ks = strings.Split(k, KeySep)
+		var cur = r
+		for i, kk := range ks[:len(ks)-1] {
+			if leaves[strings.Join(ks[:i+1], KeySep)] {
+				continue keys
+			}
+			var nm, ok = cur[kk].(map[string]interface{})
+			if !ok {
+				nm = make(map[string]interface{})
+				cur[kk] = nm
+			}
+			cur = nm
+		}
+		leaves[k] = true
+		cur[ks[len(ks)-1]] = m[k]
+	}
+	return r
+}
+
+// index parses the key segment k as an array index. Only canonical indexes are accepted,
+// so that 01 or +1 are not taken for 1.
+func index(k string) (int, bool) {
+	var i, err = strconv.Atoi(k)
+	if err != nil || i < 0 || strconv.Itoa(i) != k {
+		return 0, false
+	}
+	return i, true
+}
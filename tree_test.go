@@ -0,0 +1,190 @@
+package konfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlice(t *testing.T) {
+	t.Run(
+		"indexed keys",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.Set("servers", []interface{}{"ignored"})
+			c.Set("servers.0.host", "a.local")
+			c.Set("servers.0.port", 80)
+			c.Set("servers.0.tags", []interface{}{"foo"})
+			c.Set("servers.0.tags.0", "foo")
+			c.Set("servers.2.host", "c.local")
+			c.Set("servers.2.db.name", "bar")
+			c.Set("servers.foo", "bar")
+			c.Set("servers.3", "scalar")
+
+			require.Equal(
+				t,
+				[]map[string]interface{}{
+					{
+						"host": "a.local",
+						"port": 80,
+						"tags": []interface{}{"foo"},
+					},
+					{
+						"host": "c.local",
+						"db":   map[string]interface{}{"name": "bar"},
+					},
+				},
+				c.Slice("servers"),
+			)
+		},
+	)
+
+	t.Run(
+		"sparse indexes",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.Set("servers.999999999.host", "b.local")
+			c.Set("servers.1.host", "a.local")
+
+			require.Equal(
+				t,
+				[]map[string]interface{}{
+					{"host": "a.local"},
+					{"host": "b.local"},
+				},
+				c.Slice("servers"),
+			)
+		},
+	)
+
+	t.Run(
+		"same shape whatever the number of subkeys",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.Set("p.0.a", 1)
+			c.Set("p.0.b", 2)
+			c.Set("p.3.a", 3)
+
+			require.Equal(
+				t,
+				[]map[string]interface{}{
+					{"a": 1, "b": 2},
+					{"a": 3},
+				},
+				c.Slice("p"),
+			)
+
+			c = New(DefaultConfig())
+			c.Set("p.0.a", 1)
+			c.Set("p.0.b", 2)
+			c.Set("p.0.c", 3)
+			c.Set("p.0.d", 4)
+			c.Set("p.3.a", 5)
+
+			require.Equal(
+				t,
+				[]map[string]interface{}{
+					{"a": 1, "b": 2, "c": 3, "d": 4},
+					{"a": 5},
+				},
+				c.Slice("p"),
+			)
+		},
+	)
+
+	t.Run(
+		"non canonical indexes",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.Set("servers.1.host", "a.local")
+			c.Set("servers.01.host", "b.local")
+			c.Set("servers.+1.host", "c.local")
+			c.Set("servers.-0.host", "d.local")
+
+			require.Equal(
+				t,
+				[]map[string]interface{}{
+					{"host": "a.local"},
+				},
+				c.Slice("servers"),
+			)
+		},
+	)
+
+	t.Run(
+		"no indexed keys",
+		func(t *testing.T) {
+			var c = New(DefaultConfig())
+			c.Set("servers", []interface{}{"foo"})
+			require.Nil(t, c.Slice("servers"))
+		},
+	)
+}
+
+func TestIndexedKeysOverride(t *testing.T) {
+	type Config struct {
+		Endpoints []Endpoint `konfig:"endpoints"`
+	}
+
+	var c = New(DefaultConfig())
+	c.Bind(Config{})
+	c.RegisterLoader(&valuesLoader{values: Values{
+		"endpoints": []interface{}{
+			map[string]interface{}{"host": "a", "port": 80},
+			map[string]interface{}{"host": "b", "port": 81},
+		},
+		"endpoints.0.host": "a",
+		"endpoints.0.port": 80,
+		"endpoints.1.host": "b",
+		"endpoints.1.port": 81,
+	}})
+	var env = &valuesLoader{values: Values{"endpoints.0.host": "env"}}
+	c.RegisterLoader(env).WithPriority(1)
+	require.Nil(t, c.Load())
+
+	// the whole array reflects the indexed keys of other loaders
+	var endpoints = []interface{}{
+		map[string]interface{}{"host": "env", "port": 80},
+		map[string]interface{}{"host": "b", "port": 81},
+	}
+	require.Equal(t, endpoints, c.Get("endpoints"))
+	require.Equal(t, endpoints, c.Tree("")["endpoints"])
+	require.Equal(t, "env", c.Slice("endpoints")[0]["host"])
+	require.Equal(t, "env", c.Value().(Config).Endpoints[0].Host)
+	require.Equal(t, 80, c.Value().(Config).Endpoints[0].Port)
+
+	// as well as set values and new indexes
+	require.Nil(t, c.Set("endpoints.1.port", 8081))
+	require.Nil(t, c.Set("endpoints.5.host", "c"))
+	var v = c.Value().(Config)
+	require.Len(t, v.Endpoints, 3)
+	require.Equal(t, 8081, v.Endpoints[1].Port)
+	require.Equal(t, "c", v.Endpoints[2].Host)
+
+	// arrays without overridden indexed keys are left as they are
+	var c2 = New(DefaultConfig())
+	var tags = []string{"a", "b"}
+	c2.RegisterLoader(&valuesLoader{values: Values{"tags": tags, "tags.0": "a", "tags.1": "b"}})
+	require.Nil(t, c2.Load())
+	require.Equal(t, tags, c2.Get("tags"))
+}
+
+func TestKeysTree(t *testing.T) {
+	var c = New(DefaultConfig())
+	c.Set("db.host", "localhost")
+	c.Set("db.pool.size", 10)
+	c.Set("dbx", "foo")
+	c.Set("port", 8080)
+
+	require.Equal(t, []string{"db.host", "db.pool.size"}, c.Keys("db"))
+	require.Equal(t, []string{"db.host", "db.pool.size", "dbx", "port"}, c.Keys(""))
+	require.Equal(
+		t,
+		map[string]interface{}{
+			"host": "localhost",
+			"pool": map[string]interface{}{"size": 10},
+		},
+		c.Tree("db"),
+	)
+	require.Equal(t, map[string]interface{}{}, c.Tree("foo"))
+}
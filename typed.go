@@ -0,0 +1,69 @@
+package konfig
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ErrConvertMsg is the error message returned when a config value cannot be converted to the requested type
+var ErrConvertMsg = "Err config '%s' cannot be converted to %T"
+
+// GetAs gets the value with the key k from s (a Store or a Snapshot) and converts it to T.
+// Conversions are the same as for bound values. It returns a non nil error if the key is not set
+// or if the value cannot be converted to T.
+func GetAs[T any](s Reader, k string) (T, error) {
+	var zero T
+	if !s.Exists(k) {
+		return zero, fmt.Errorf(ErrConfigNotFoundMsg, k)
+	}
+	return convert[T](k, s.Get(k))
+}
+
+// MustGetAs gets the value with the key k from s (a Store or a Snapshot) and converts it to T.
+// It panics if the key is not set or if the value cannot be converted to T.
+func MustGetAs[T any](s Reader, k string) T {
+	var v, err = GetAs[T](s, k)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func convert[T any](k string, v interface{}) (T, error) {
+	var zero T
+	if tv, ok := v.(T); ok {
+		return tv, nil
+	}
+
+	var r, err = castValueE(zero, v)
+	if err != nil {
+		return zero, errors.Wrapf(err, ErrConvertMsg, k, zero)
+	}
+
+	// castValueE returns v as is when T is not supported
+	if tv, ok := r.(T); ok {
+		return tv, nil
+	}
+	return zero, fmt.Errorf(ErrConvertMsg, k, zero)
+}
+
+// Bound is a typed value bound to a Store
+type Bound[T any] struct {
+	b *Binding
+}
+
+// BindAs binds a value of type T (either a map[string]interface{} or a struct) to the keys of the store s
+// and returns a Bound to access it without type assertion. The value is bound with BindPrefix,
+// so it is independent of the value bound with Bind and of the values bound by other calls to BindAs.
+// It panics if T is neither a map[string]interface{} nor a struct.
+func BindAs[T any](s Store) *Bound[T] {
+	var zero T
+	return &Bound[T]{b: s.BindPrefix("", zero)}
+}
+
+// Load returns the value bound to the store
+func (b *Bound[T]) Load() T {
+	var v, _ = b.b.Value().(T)
+	return v
+}
@@ -0,0 +1,122 @@
+package konfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAs(t *testing.T) {
+	var testCases = []struct {
+		name    string
+		set     interface{}
+		get     func(s Store) (interface{}, error)
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "int from string",
+			set:  "8080",
+			get: func(s Store) (interface{}, error) {
+				return GetAs[int](s, "foo")
+			},
+			want: 8080,
+		},
+		{
+			name: "int invalid string",
+			set:  "abc",
+			get: func(s Store) (interface{}, error) {
+				return GetAs[int](s, "foo")
+			},
+			wantErr: true,
+		},
+		{
+			name: "duration",
+			set:  "1s",
+			get: func(s Store) (interface{}, error) {
+				return GetAs[time.Duration](s, "foo")
+			},
+			want: time.Second,
+		},
+		{
+			name: "same type",
+			set:  []float64{1, 2},
+			get: func(s Store) (interface{}, error) {
+				return GetAs[[]float64](s, "foo")
+			},
+			want: []float64{1, 2},
+		},
+		{
+			name: "unsupported type",
+			set:  "foo",
+			get: func(s Store) (interface{}, error) {
+				return GetAs[[]float64](s, "foo")
+			},
+			wantErr: true,
+		},
+		{
+			name: "key not found",
+			get: func(s Store) (interface{}, error) {
+				return GetAs[string](s, "bar")
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(
+			testCase.name,
+			func(t *testing.T) {
+				var s = New(DefaultConfig())
+				s.Set("foo", testCase.set)
+
+				var v, err = testCase.get(s)
+				if testCase.wantErr {
+					require.NotNil(t, err)
+					return
+				}
+				require.Nil(t, err)
+				require.Equal(t, testCase.want, v)
+			},
+		)
+	}
+}
+
+func TestMustGetAs(t *testing.T) {
+	var s = New(DefaultConfig())
+	s.Set("foo", "1")
+
+	require.Equal(t, true, MustGetAs[bool](s, "foo"))
+	require.Panics(t, func() { MustGetAs[int](s, "bar") })
+}
+
+func TestBindAs(t *testing.T) {
+	type testConfig struct {
+		Foo string `konfig:"foo"`
+	}
+
+	var s = New(DefaultConfig())
+	var b = BindAs[testConfig](s)
+
+	require.Equal(t, testConfig{}, b.Load())
+
+	s.Set("foo", "bar")
+	require.Equal(t, testConfig{Foo: "bar"}, b.Load())
+
+	// values bound with BindAs are independent of each other
+	type otherConfig struct {
+		Foo string `konfig:"foo"`
+		Bar int    `konfig:"bar" default:"1"`
+	}
+	var o = BindAs[otherConfig](s)
+	require.Equal(t, testConfig{Foo: "bar"}, b.Load())
+	require.Equal(t, otherConfig{Foo: "bar", Bar: 1}, o.Load())
+
+	// values can be bound to the keys of a view
+	s.Set("sub.foo", "baz")
+	var sub = BindAs[testConfig](s.Sub("sub"))
+	require.Equal(t, testConfig{Foo: "baz"}, sub.Load())
+	s.Set("sub.foo", "qux")
+	require.Equal(t, testConfig{Foo: "qux"}, sub.Load())
+}
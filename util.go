@@ -33,6 +33,7 @@ func Exists(k string) bool {
 
 // Exists checks if a config key k is set in the Store
 func (c *S) Exists(k string) bool {
+	k = c.canonical(k)
 	var m = c.m.Load().(s)
 	_, ok := m[k]
 	return ok
@@ -51,33 +52,97 @@ func MustGet(k string) interface{} {
 }
 
 // Set will set the key value to the sync.Map
-func Set(k string, v interface{}) {
-	instance().Set(k, v)
+func Set(k string, v interface{}) error {
+	return instance().Set(k, v)
 }
 
-// Set sets a value in config
-func (c *S) Set(k string, v interface{}) {
+// Set sets a value in config, references in the value are resolved if Interpolation is enabled.
+// The new values are checked as a load: if they fail the checks or if the references in the value
+// cannot be resolved, the error is logged and returned and the store is left untouched.
+// Change hooks, key hooks and subscribers are notified of the changes.
+func (c *S) Set(k string, v interface{}) error {
+	k = c.canonical(k)
 	c.mut.Lock()
-	defer c.mut.Unlock()
 
-	var m = c.m.Load().(s)
+	// we keep the value in the set layer so that it is kept when loaders reload,
+	// the set layer is copied as it is referenced by snapshots
+	var sets, setAt = c.sets, c.setAt
+	c.sets = make(Values, len(sets)+1)
+	for kk, vv := range sets {
+		c.sets[kk] = vv
+	}
+	c.sets[k] = v
+	c.setAt = time.Now()
+
+	// an overridden key keeps the value of the override
+	var cs, err = c.applyLocked(nil, nil, OriginSet, false)
+	if err != nil {
+		c.sets, c.setAt = sets, setAt
+		c.mut.Unlock()
+		c.cfg.Logger.Get().Error("Error while setting value, the value is not set: " + err.Error())
+		return err
+	}
+
+	var changeHooks = c.changeHooks
+	c.mut.Unlock()
+
+	if err := c.runChangeHooks(changeHooks, cs); err != nil {
+		c.cfg.Logger.Get().Error("Error while running change hooks: " + err.Error())
+		return err
+	}
+	return nil
+}
 
-	var nm = make(s)
-	for kk, vv := range m {
-		nm[kk] = vv
+// deletedKey is the value stored in the set layer for the keys deleted with Delete
+type deletedKey struct{}
+
+// Delete removes the key k from the global store
+func Delete(k string) error {
+	return instance().Delete(k)
+}
+
+// Delete removes the key k from the store along with the values set for it with Set or Override.
+// Loaders setting the key set it again on their next load, loads of other loaders don't restore it.
+// The new values are checked as a load, change hooks and key hooks run when the key is deleted.
+func (c *S) Delete(k string) error {
+	k = c.canonical(k)
+	c.mut.Lock()
+
+	if _, ok := c.raw[k]; !ok {
+		c.mut.Unlock()
+		return nil
 	}
-	nm[k] = v
 
-	// if there is a value bound we set it there also
-	if c.v != nil {
-		c.v.set(k, v)
+	// the key is deleted in the set layer so that it stays deleted until a loader loads it again,
+	// the set layer is copied as it is referenced by snapshots
+	var sets, overrides = c.sets, c.overrides
+	c.sets = make(Values, len(sets)+1)
+	for kk, vv := range sets {
+		c.sets[kk] = vv
+	}
+	c.sets[k] = deletedKey{}
+	var o = c.removeOverride(k)
+
+	// the key cannot be deleted if values reference it without a default
+	var cs, err = c.applyLocked(nil, nil, OriginDelete, false)
+	if err != nil {
+		c.sets, c.overrides = sets, overrides
+		c.mut.Unlock()
+		return err
 	}
+	if o != nil && o.timer != nil {
+		o.timer.Stop()
+	}
+
+	var changeHooks = c.changeHooks
+	c.mut.Unlock()
 
-	c.m.Store(nm)
+	return c.runChangeHooks(changeHooks, cs)
 }
 
 // Get gets a value from config
 func (c *S) Get(k string) interface{} {
+	k = c.canonical(k)
 	var m = c.m.Load().(s)
 	if v, ok := m[k]; ok {
 		return v
@@ -87,6 +152,7 @@ func (c *S) Get(k string) interface{} {
 
 // MustGet gets a value from config and panics if the value does not exist
 func (c *S) MustGet(k string) interface{} {
+	k = c.canonical(k)
 	var m = c.m.Load().(s)
 	if v, ok := m[k]; ok {
 		return v
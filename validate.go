@@ -0,0 +1,62 @@
+package konfig
+
+import (
+	"fmt"
+
+	multierror "github.com/hashicorp/go-multierror"
+)
+
+// ErrValidationMsg is the error message returned when a config value is invalid
+var ErrValidationMsg = "Err config '%s' is invalid: %s"
+
+// Validator is the interface to validate the values of a store before they are committed
+type Validator interface {
+	// Validate returns a non nil error if the values of the snapshot are invalid.
+	// It should report all violations at once, for example in a multierror.
+	Validate(*Snapshot) error
+}
+
+// ValidatorFunc is a function implementing Validator
+type ValidatorFunc func(*Snapshot) error
+
+// Validate implements Validator
+func (f ValidatorFunc) Validate(sn *Snapshot) error {
+	return f(sn)
+}
+
+// ValidationError is the error returned when the value of a key is invalid
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf(ErrValidationMsg, e.Key, e.Message)
+}
+
+// RegisterValidator adds a validator to the global store
+func RegisterValidator(v Validator) Store {
+	return instance().RegisterValidator(v)
+}
+
+// RegisterValidator adds a validator run on the new values of the store before they are committed.
+// On the first Load, the values of all loaders are validated before the values of the last loader are committed.
+// Once the store is loaded, a reload producing invalid values is rejected and the last valid values are kept.
+func (c *S) RegisterValidator(v Validator) Store {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.validators = append(c.validators, v)
+	return c
+}
+
+// validate runs all validators and returns all violations in a single error
+func (c *S) validate(sn *Snapshot) error {
+	var multiErr *multierror.Error
+	for _, v := range c.validators {
+		if err := v.Validate(sn); err != nil {
+			multiErr = multierror.Append(multiErr, err)
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
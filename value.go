@@ -1,15 +1,17 @@
 package konfig
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/jinzhu/copier"
+	"github.com/pkg/errors"
 	"github.com/spf13/cast"
 )
 
@@ -28,11 +30,12 @@ var (
 )
 
 type value struct {
-	s     *S
-	v     *atomic.Value
-	vt    reflect.Type
-	mut   *sync.Mutex
-	isMap bool
+	s      *S
+	v      *atomic.Value
+	vt     reflect.Type
+	mut    *sync.Mutex
+	isMap  bool
+	schema *Schema
 }
 
 // Value returns the value bound to the root config store
@@ -57,17 +60,36 @@ func (c *S) Value() interface{} {
 
 // Bind binds a value (either a map[string]interface{} or a struct) to the config store.
 // When config values are set on the config store, they are also set on the bound value.
+// Struct fields support the default, validate and secret tags, the bound value is validated before it is updated.
+// Values which cannot be decoded into their field are logged, with Config.StrictBinding the load fails instead.
 func (c *S) Bind(v interface{}) {
+	var val = c.newValue(v, ErrIncorrectValue)
+
+	c.v = val
+
+	// set the values already in the store
+	if err := val.setValues(c.m.Load().(s)); err != nil {
+		c.cfg.Logger.Get().Error(err.Error())
+	}
+
+	// seed the default values and read validation rules from the struct tags
+	if !val.isMap {
+		val.schema = c.bindTags(val.vt, "")
+	}
+}
+
+// newValue returns a new value of the type of v, it panics with errIncorrect if v is neither a map nor a struct
+func (c *S) newValue(v interface{}, errIncorrect error) *value {
 	var t = reflect.TypeOf(v)
 	var k = t.Kind()
 	//  if it is neither a map nor a struct
 	if k != reflect.Map && k != reflect.Struct {
-		panic(ErrIncorrectValue)
+		panic(errIncorrect)
 	}
 	// if it is a map check map[string]interface{}
 	if k == reflect.Map &&
 		(t.Key().Kind() != reflect.String || t.Elem().Kind() != reflect.Interface) {
-		panic(ErrIncorrectValue)
+		panic(errIncorrect)
 	}
 
 	var val = &value{
@@ -85,10 +107,11 @@ func (c *S) Bind(v interface{}) {
 
 	val.v = &atomicValue
 
-	c.v = val
+	return val
 }
 
 // BindStructStrict binds a value (must a struct) to the config store and adds the exposed fields as strick keys.
+// Fields with the tag `required:"false"` are not strict.
 func (c *S) BindStructStrict(v interface{}) {
 	var t = reflect.TypeOf(v)
 	var k = t.Kind()
@@ -102,69 +125,32 @@ func (c *S) BindStructStrict(v interface{}) {
 	c.Bind(v)
 }
 
+// getStructKeys returns the keys of the fields of the struct type t which are not opted out of strict keys
 func getStructKeys(t reflect.Type, prefix string) []string {
 	var keys []string
-	for i := 0; i < t.NumField(); i++ {
-		var fieldValue = t.Field(i)
-		var tag = fieldValue.Tag.Get(TagKey)
-
-		if tag == "-" {
+	for _, f := range structFields(t, prefix) {
+		if f.field.Tag.Get(TagRequired) == "false" {
 			continue
 		}
-
-		// use field name when konfig tag is not specified
-		if tag == "" {
-			tag = strings.ToLower(fieldValue.Name)
-		}
-
-		if fieldValue.Type.Kind() == reflect.Struct {
-			structKeys := getStructKeys(fieldValue.Type, tag+KeySep)
-			keys = append(keys, structKeys...)
-
-			// don't add the parent tag
-			continue
-		}
-
-		keys = append(keys, prefix+tag)
+		keys = append(keys, f.key)
 	}
 
 	return keys
 }
 
-func (val *value) set(k string, v interface{}) {
-	val.mut.Lock()
-	defer val.mut.Unlock()
-
-	var configValue = val.v.Load()
-
-	// if value is a map
-	// store things in a map
-	if val.isMap {
-		var mapV = configValue.(map[string]interface{})
-		var nMap = make(map[string]interface{})
-
-		for kk, vv := range mapV {
-			nMap[kk] = vv
-		}
-
-		nMap[k] = v
-
-		val.v.Store(nMap)
-		return
+// setValues sets the values x on a new bound value, the bound value is replaced only if all values can be decoded
+func (val *value) setValues(x s) error {
+	var nv, err = val.build(x)
+	if err != nil {
+		return err
 	}
-
-	// make a copy
-	var t = reflect.TypeOf(configValue)
-	var nVal = reflect.New(t)
-
-	copier.Copy(nVal.Interface(), configValue)
-
-	val.setStruct(k, v, nVal)
-
-	val.v.Store(nVal.Elem().Interface())
+	val.v.Store(nv)
+	return nil
 }
 
-func (val *value) setValues(x s) {
+// build returns a new bound value with the values x. If some values cannot be decoded,
+// it returns the errors along with the bound value without them.
+func (val *value) build(x s) (interface{}, error) {
 	val.mut.Lock()
 	defer val.mut.Unlock()
 
@@ -184,22 +170,32 @@ func (val *value) setValues(x s) {
 			nMap[kk] = vv
 		}
 
-		val.v.Store(nMap)
-		return
+		return nMap, nil
 	}
 
 	// make a copy
 	var t = reflect.TypeOf(configValue)
 	var nVal = reflect.New(t)
 
-	for kk, vv := range x {
-		val.setStruct(kk, vv, nVal)
+	// keys are sorted so that errors are reported in a stable order
+	var keys = make([]string, 0, len(x))
+	for kk := range x {
+		keys = append(keys, kk)
 	}
+	sort.Strings(keys)
 
-	val.v.Store(nVal.Elem().Interface())
+	var multiErr *multierror.Error
+	for _, kk := range keys {
+		if _, err := val.setStruct(kk, x[kk], nVal); err != nil {
+			multiErr = multierror.Append(multiErr, errors.Wrapf(err, ErrDecodeMsg, kk))
+		}
+	}
+	return nVal.Elem().Interface(), multiErr.ErrorOrNil()
 }
 
-func (val *value) setStruct(k string, v interface{}, targetValue reflect.Value) bool {
+// setStruct sets the key k with the value v on the struct targetValue points to.
+// It returns whether a field matched the key and a non nil error if v cannot be decoded into the field.
+func (val *value) setStruct(k string, v interface{}, targetValue reflect.Value) (bool, error) {
 
 	// is a struct, find matching tag
 	var valTypePtr = targetValue.Type()
@@ -211,14 +207,18 @@ func (val *value) setStruct(k string, v interface{}, targetValue reflect.Value)
 	for i := 0; i < valType.NumField(); i++ {
 		var fieldValue = valType.Field(i)
 		var fieldName = fieldValue.Name
-		var tag = fieldValue.Tag.Get(TagKey)
+		var tag = val.s.normalize(fieldValue.Tag.Get(TagKey))
 
 		// check tag, if it matches key
 		// assign v to field
 		if tag == k || strings.EqualFold(fieldName, k) {
 			var field = valValue.FieldByName(fieldValue.Name)
 			if field.CanSet() {
-				field.Set(reflect.ValueOf(castValue(field.Interface(), v)))
+				var nv, err = val.decode(field.Type(), v)
+				if err != nil {
+					return set, err
+				}
+				field.Set(nv)
 			}
 			set = true
 			continue
@@ -237,7 +237,7 @@ func (val *value) setStruct(k string, v interface{}, targetValue reflect.Value)
 
 			switch fieldValue.Type.Kind() {
 			// Is a map.
-			// Only map[string]someStruct is supported.
+			// map[string]someStruct and maps of values keyed by strings are supported.
 			// The idea is to be able to store lists of key value where the keys are not known.
 			case reflect.Map:
 				var keyKind = fieldValue.Type.Key().Kind()
@@ -249,12 +249,33 @@ func (val *value) setStruct(k string, v interface{}, targetValue reflect.Value)
 					var structType reflect.Type
 					var ptr bool
 
-					if eltKind == reflect.Struct {
+					if eltKind == reflect.Struct && !isLeaf(fieldValue.Type.Elem()) {
 						structType = fieldValue.Type.Elem()
-					} else if eltKind == reflect.Ptr && fieldValue.Type.Elem().Elem().Kind() == reflect.Struct {
+					} else if eltKind == reflect.Ptr &&
+						fieldValue.Type.Elem().Elem().Kind() == reflect.Struct &&
+						!isLeaf(fieldValue.Type.Elem()) {
 						structType = fieldValue.Type.Elem().Elem()
 						ptr = true
 					} else {
+						// the rest of the key is the map key
+						var field = valValue.FieldByName(fieldValue.Name)
+						if !field.CanSet() {
+							continue
+						}
+						var nv, err = val.decode(fieldValue.Type.Elem(), v)
+						if err != nil {
+							return set, err
+						}
+						var mapVal = reflect.MakeMap(fieldValue.Type)
+						if !field.IsNil() {
+							var iter = field.MapRange()
+							for iter.Next() {
+								mapVal.SetMapIndex(iter.Key(), iter.Value())
+							}
+						}
+						mapVal.SetMapIndex(reflect.ValueOf(nK).Convert(fieldValue.Type.Key()), nv)
+						field.Set(mapVal)
+						set = true
 						continue
 					}
 
@@ -283,11 +304,15 @@ func (val *value) setStruct(k string, v interface{}, targetValue reflect.Value)
 						}
 
 						// we set the field with the new struct
-						if ok := val.setStruct(
+						var ok, err = val.setStruct(
 							keyElt[1],
 							v,
 							nVal,
-						); ok {
+						)
+						if err != nil {
+							return set, err
+						}
+						if ok {
 							if !ptr {
 								mapVal.SetMapIndex(mapKeyVal, nVal.Elem())
 							} else {
@@ -301,7 +326,7 @@ func (val *value) setStruct(k string, v interface{}, targetValue reflect.Value)
 			case reflect.Struct:
 				var field = valValue.FieldByName(fieldValue.Name)
 				// if field can be set
-				if field.CanSet() {
+				if field.CanSet() && !isLeaf(field.Type()) {
 					var structType = field.Type()
 					var nVal = reflect.New(structType)
 
@@ -309,7 +334,11 @@ func (val *value) setStruct(k string, v interface{}, targetValue reflect.Value)
 					copier.Copy(nVal.Interface(), field.Interface())
 
 					// we set the field with the new struct
-					if ok := val.setStruct(nK, v, nVal); ok {
+					var ok, err = val.setStruct(nK, v, nVal)
+					if err != nil {
+						return set, err
+					}
+					if ok {
 						field.Set(nVal.Elem())
 						set = true
 					}
@@ -317,7 +346,7 @@ func (val *value) setStruct(k string, v interface{}, targetValue reflect.Value)
 					continue
 				}
 			case reflect.Ptr:
-				if fieldValue.Type.Elem().Kind() == reflect.Struct {
+				if fieldValue.Type.Elem().Kind() == reflect.Struct && !isLeaf(fieldValue.Type) {
 					var field = valValue.FieldByName(fieldValue.Name)
 					if field.CanSet() {
 						var nVal = reflect.New(fieldValue.Type.Elem())
@@ -328,7 +357,11 @@ func (val *value) setStruct(k string, v interface{}, targetValue reflect.Value)
 							copier.Copy(nVal.Interface(), field.Interface())
 						}
 
-						if ok := val.setStruct(nK, v, nVal); ok {
+						var ok, err = val.setStruct(nK, v, nVal)
+						if err != nil {
+							return set, err
+						}
+						if ok {
 							field.Set(nVal)
 							set = true
 						}
@@ -348,41 +381,48 @@ func (val *value) setStruct(k string, v interface{}, targetValue reflect.Value)
 		)
 	}
 
-	return set
+	return set, nil
 }
 
 func castValue(f interface{}, v interface{}) interface{} {
+	var r, _ = castValueE(f, v)
+	return r
+}
+
+// castValueE casts v to the type of f. If the type of f is not supported, v is returned as is.
+// It returns a non nil error if v cannot be cast.
+func castValueE(f interface{}, v interface{}) (interface{}, error) {
 	switch f.(type) {
 	case string:
-		return cast.ToString(v)
+		return cast.ToStringE(v)
 	case bool:
-		return cast.ToBool(v)
+		return cast.ToBoolE(v)
 	case int:
-		return cast.ToInt(v)
+		return cast.ToIntE(v)
 	case int64:
-		return cast.ToInt64(v)
+		return cast.ToInt64E(v)
 	case int32:
-		return cast.ToInt32(v)
+		return cast.ToInt32E(v)
 	case float64:
-		return cast.ToFloat64(v)
+		return cast.ToFloat64E(v)
 	case float32:
-		return cast.ToFloat32(v)
+		return cast.ToFloat32E(v)
 	case uint64:
-		return cast.ToUint64(v)
+		return cast.ToUint64E(v)
 	case uint32:
-		return cast.ToUint32(v)
+		return cast.ToUint32E(v)
 	case uint8:
-		return cast.ToUint8(v)
+		return cast.ToUint8E(v)
 	case []string:
-		return cast.ToStringSlice(v)
+		return cast.ToStringSliceE(v)
 	case []int:
-		return cast.ToIntSlice(v)
+		return cast.ToIntSliceE(v)
 	case time.Time:
-		return cast.ToTime(v)
+		return cast.ToTimeE(v)
 	case time.Duration:
-		return cast.ToDuration(v)
+		return cast.ToDurationE(v)
 	case map[string]string:
-		return cast.ToStringMapString(v)
+		return cast.ToStringMapStringE(v)
 	}
-	return v
+	return v, nil
 }
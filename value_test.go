@@ -216,9 +216,9 @@ func TestSetStruct(t *testing.T) {
 				"subt.notfound":     1,
 			}
 
-			v.load(Values{
-				"v": "a",
-			}, instance())
+			var wl = &loaderWatcher{values: Values{"v": "a"}}
+			var _, err = instance().apply(map[*loaderWatcher]Values{wl: v}, wl, false)
+			require.Nil(t, err)
 
 			var configValue = Value().(TestConfig)
 			require.Equal(t, "test", configValue.V)
@@ -272,7 +272,8 @@ func TestSetStruct(t *testing.T) {
 				"sub.vv": "test2",
 			}
 
-			vv.load(v, instance())
+			_, err = instance().apply(map[*loaderWatcher]Values{wl: vv}, wl, false)
+			require.Nil(t, err)
 
 			configValue = Value().(TestConfig)
 			require.Equal(t, "test", configValue.V)
@@ -366,7 +367,9 @@ func TestSetStruct(t *testing.T) {
 				"subt.tt": 2,
 			}
 
-			v.load(Values{}, instance())
+			var wl = &loaderWatcher{}
+			var _, err = instance().apply(map[*loaderWatcher]Values{wl: v}, wl, false)
+			require.Nil(t, err)
 
 			var configValue = Value().(map[string]interface{})
 			require.Equal(t, "test", configValue["v"])
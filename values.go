@@ -1,7 +1,7 @@
 package konfig
 
 import (
-	"reflect"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -14,55 +14,154 @@ func (x Values) Set(k string, v interface{}) {
 	x[k] = v
 }
 
-func (x Values) load(ox Values, c *S) ([]string, error) {
+// apply computes the new store values from the staged values and the values of all other loaders.
+// The new values are checked and committed only if all checks and pre-commit hooks pass,
+// else the store is left untouched. wl is the loader causing the changes, it is nil when
+// multiple loaders are applied. If strict is false, strict keys are checked and values are validated
+// only once the store has been loaded.
+func (c *S) apply(staged map[*loaderWatcher]Values, wl *loaderWatcher, strict bool) (ChangeSet, error) {
 	c.mut.Lock()
 	defer c.mut.Unlock()
 
+	return c.applyLocked(staged, wl, OriginReload, strict)
+}
+
+// applyLocked is apply for callers holding the lock of the store,
+// loader is the name reported in the changes when wl is nil.
+func (c *S) applyLocked(staged map[*loaderWatcher]Values, wl *loaderWatcher, loader string, strict bool) (ChangeSet, error) {
+	return c.applyLockedForce(staged, wl, loader, strict, false)
+}
+
+// applyLockedForce is applyLocked, if force is true the new values are committed even if they fail the checks:
+// the error of the checks is returned along with the changes and recorded in the health of the store
+// until values passing the checks are committed.
+func (c *S) applyLockedForce(staged map[*loaderWatcher]Values, wl *loaderWatcher, loader string, strict, force bool) (ChangeSet, error) {
 	// load the previous key store
 	var m = c.m.Load().(s)
 
-	// we copy the previous store
-	// but we omit what was on the previous values
-	var updatedKeys = make([]string, 0, len(x))
-	var nm = make(s)
-	for kk, vv := range m {
-		// if key is not in old loader values
-		// add it to the new store
-		// else if key is in new loader values but value is different or key is
-		// in previous values but not in new ones
-		// we add it to updatedKeys list
-		if _, ok := ox[kk]; !ok {
-			nm[kk] = vv
-		} else if v, ok := x[kk]; (ok && !reflect.DeepEqual(v, vv)) || !ok { // value is different or not present anymore
-			updatedKeys = append(updatedKeys, kk)
+	// we merge the values of all loaders
+	var t = time.Now()
+	var layers = c.layers
+
+	// we normalize the keys of the values being loaded
+	var rawKeys map[*loaderWatcher]map[string]string
+	if c.cfg.KeyNormalizer != nil {
+		var nstaged = make(map[*loaderWatcher]Values, len(staged))
+		rawKeys = make(map[*loaderWatcher]map[string]string, len(staged))
+		for lw, x := range staged {
+			nstaged[lw], rawKeys[lw] = c.normalizeValues(lw, x)
+			c.reportCollisions(lw, nstaged[lw], rawKeys[lw])
 		}
+		staged = nstaged
+	}
+
+	// on failure the layers and the values set with Set are restored
+	var sets = c.releaseSets(staged)
+	var restore = func() {
+		c.layers = layers
+		c.sets = sets
 	}
-	// we add the new values
-	for kk, vv := range x {
-		nm[kk] = vv
-		// if key is not present in old store, add it to updatedKeys as we are adding a new key in
-		if _, ok := m[kk]; !ok {
-			updatedKeys = append(updatedKeys, kk)
+
+	for lw := range staged {
+		c.addLayer(lw)
+	}
+	var raw, no = c.merge(staged, t)
+
+	// we resolve the references in the values
+	var nm, err = c.interpolate(raw)
+	if err != nil {
+		c.cfg.Logger.Get().Error("Error while interpolating values: " + err.Error())
+		restore()
+		return ChangeSet{}, err
+	}
+
+	var sn = c.newSnapshot(nm, t)
+	var invalid = c.check(sn, raw, strict)
+	if invalid != nil {
+		if !force {
+			restore()
+			return ChangeSet{}, invalid
 		}
+		// the checks stopped at the first failure, the bound values are set nonetheless
+		if err := c.setBound(nm); err != nil {
+			c.cfg.Logger.Get().Error("Error while setting bound value: " + err.Error())
+		}
+		c.invalid, c.invalidAt = invalid, t
+	} else {
+		c.invalid = nil
+	}
+
+	// we didn't get any error, store the new config state
+	for lw, x := range staged {
+		lw.values = x
+		lw.rawKeys = rawKeys[lw]
+		lw.loadedAt = t
+	}
+	c.raw = raw
+
+	// the values did not change, there is nothing to publish
+	if !c.commit(sn, no) {
+		return ChangeSet{}, invalid
+	}
+
+	var cs = diff(m, nm)
+	if wl == nil {
+		cs.Loader = loader
 	}
+	c.publish(&cs, wl, t)
+
+	return cs, invalid
+}
+
+// check runs the checks new values must pass before being committed and sets the bound values,
+// raw holds the values of sn before interpolation.
+// If strict is false, strict keys are checked and values are validated only once the store has been loaded.
+func (c *S) check(sn *Snapshot, raw s, strict bool) error {
+	var nm = sn.m
 
 	// if we have strict keys setup on the store and we have already loaded configs
 	// we check those keys now, if they are not present, we will return the error.
-	if c.strictKeys != nil && c.loaded {
-		if err := nm.checkStrictKeys(c.strictKeys); err != nil {
-			err = errors.Wrap(err, "Error while checking strict keys")
-			c.cfg.Logger.Get().Error(err.Error())
-			return nil, err
+	// the values are validated the same way.
+	if strict || c.loaded {
+		if c.strictKeys != nil {
+			if err := nm.checkStrictKeys(c.canonicalKeys(c.strictKeys)); err != nil {
+				err = errors.Wrap(err, "Error while checking strict keys")
+				c.cfg.Logger.Get().Error(err.Error())
+				return err
+			}
+		}
+
+		// validators see the secret keys of the store, including keys referencing secrets in raw
+		sn.secret = func(k string) bool {
+			return c.isSecret(sn.canonical(k), raw, make(map[string]struct{}))
+		}
+		var err = c.validate(sn)
+		sn.secret = nil
+		if err != nil {
+			c.cfg.Logger.Get().Error("Error while validating values: " + err.Error())
+			return err
 		}
 	}
 
-	// if there is a value bound we set it there also
-	if c.v != nil {
-		c.v.setValues(nm)
+	// the bound values are validated before they are published
+	if err := c.validateBound(sn); err != nil {
+		c.cfg.Logger.Get().Error("Error while validating bound value: " + err.Error())
+		return err
 	}
 
-	// we didn't get any error, store the new config state
-	c.m.Store(nm)
+	// we run the pre-commit hooks which can veto the new values
+	if err := c.preCommitHooks.run(sn); err != nil {
+		err = errors.Wrap(err, "Error while running pre-commit hooks")
+		c.cfg.Logger.Get().Error(err.Error())
+		return err
+	}
+
+	// if there are values bound we set the values there also,
+	// with StrictBinding if the values cannot be decoded into the bound values the load fails
+	if err := c.setBound(nm); err != nil {
+		c.cfg.Logger.Get().Error("Error while setting bound value: " + err.Error())
+		return err
+	}
 
-	return updatedKeys, nil
+	return nil
 }
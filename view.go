@@ -0,0 +1,207 @@
+package konfig
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+var _ Reader = (*Snapshot)(nil)
+
+// Reader is the interface to read values, it is implemented by Store and Snapshot
+type Reader interface {
+	// Exists checks whether the key k is set.
+	Exists(k string) bool
+	// Get gets the value with the key k. If the key is not set, Get returns nil.
+	Get(k string) interface{}
+	// MustGet gets the value with the key k. If the key is not set, MustGet panics.
+	MustGet(k string) interface{}
+	// MustInt gets the value with the key k and casts it to an int. If the key is not set, MustInt panics.
+	MustInt(k string) int
+	// Int gets the value with the key k and casts it to an int. If the key is not set, it returns the zero value.
+	Int(k string) int
+	// MustFloat gets the value with the key k and casts it to a float64. If the key is not set, MustFloat panics.
+	MustFloat(k string) float64
+	// Float gets the value with the key k and casts it to a float64. If the key is not set, it returns the zero value.
+	Float(k string) float64
+	// MustString gets the value with the key k and casts it to a string. If the key is not set, MustString panics.
+	MustString(k string) string
+	// String gets the value with the key k and casts it to a string. If the key is not set, it returns the zero value.
+	String(k string) string
+	// MustBool gets the value with the key k and casts it to a bool. If the key is not set, MustBool panics.
+	MustBool(k string) bool
+	// Bool gets the value with the key k and casts it to a bool. If the key is not set, it returns the zero value.
+	Bool(k string) bool
+	// MustDuration gets the value with the key k and casts it to a time.Duration. If the key is not set, MustDuration panics.
+	MustDuration(k string) time.Duration
+	// Duration gets the value with the key k and casts it to a time.Duration. If the key is not set, it returns the zero value.
+	Duration(k string) time.Duration
+	// MustTime gets the value with the key k and casts it to a time.Time. If the key is not set, MustTime panics.
+	MustTime(k string) time.Time
+	// Time gets the value with the key k and casts it to a time.Time. If the key is not set, it returns the zero value.
+	Time(k string) time.Time
+	// MustStringSlice gets the value with the key k and casts it to a []string. If the key is not set, MustStringSlice panics.
+	MustStringSlice(k string) []string
+	// StringSlice gets the value with the key k and casts it to a []string. If the key is not set, it returns the zero value.
+	StringSlice(k string) []string
+	// MustIntSlice gets the value with the key k and casts it to a []int. If the key is not set, MustIntSlice panics.
+	MustIntSlice(k string) []int
+	// IntSlice gets the value with the key k and casts it to a []int. If the key is not set, it returns the zero value.
+	IntSlice(k string) []int
+	// MustStringMap gets the value with the key k and casts it to a map[string]interface{}. If the key is not set, MustStringMap panics.
+	MustStringMap(k string) map[string]interface{}
+	// StringMap gets the value with the key k and casts it to a map[string]interface{}. If the key is not set, it returns the zero value.
+	StringMap(k string) map[string]interface{}
+	// MustStringMapString gets the value with the key k and casts it to a map[string]string. If the key is not set, MustStringMapString panics.
+	MustStringMapString(k string) map[string]string
+	// StringMapString gets the value with the key k and casts it to a map[string]string. If the key is not set, it returns the zero value.
+	StringMapString(k string) map[string]string
+}
+
+// View returns a read only view of the current values of the global store
+func View() *Snapshot {
+	return instance().View()
+}
+
+// View returns a read only view of the current values of the store. Values read from the view
+// are consistent with each other even if the store reloads while reading. The view is not copied.
+func (c *S) View() *Snapshot {
+	return c.Snapshot()
+}
+
+// canonical returns the normalized key k, or its new key if k is an alias
+func (sn *Snapshot) canonical(k string) string {
+	if sn.key != nil {
+		k = sn.key(k)
+	}
+	return sn.aliases.key(k)
+}
+
+// Exists checks if the key k is set in the snapshot
+func (sn *Snapshot) Exists(k string) bool {
+	return sn.m.exists(sn.canonical(k))
+}
+
+// Get gets the value with the key k from the snapshot, it returns nil if the key is not set
+func (sn *Snapshot) Get(k string) interface{} {
+	return sn.m[sn.canonical(k)]
+}
+
+// MustGet gets the value with the key k from the snapshot and panics if the key is not set
+func (sn *Snapshot) MustGet(k string) interface{} {
+	if v, ok := sn.m[sn.canonical(k)]; ok {
+		return v
+	}
+	panic(fmt.Errorf(ErrConfigNotFoundMsg, k))
+}
+
+// MustInt gets the value with the key k from the snapshot and casts it to an int, it panics if the key is not set
+func (sn *Snapshot) MustInt(k string) int {
+	return cast.ToInt(sn.MustGet(k))
+}
+
+// Int gets the value with the key k from the snapshot and casts it to an int.
+// It returns the zero value if the key is not set.
+func (sn *Snapshot) Int(k string) int {
+	return cast.ToInt(sn.Get(k))
+}
+
+// MustFloat gets the value with the key k from the snapshot and casts it to a float64, it panics if the key is not set
+func (sn *Snapshot) MustFloat(k string) float64 {
+	return cast.ToFloat64(sn.MustGet(k))
+}
+
+// Float gets the value with the key k from the snapshot and casts it to a float64.
+// It returns the zero value if the key is not set.
+func (sn *Snapshot) Float(k string) float64 {
+	return cast.ToFloat64(sn.Get(k))
+}
+
+// MustString gets the value with the key k from the snapshot and casts it to a string, it panics if the key is not set
+func (sn *Snapshot) MustString(k string) string {
+	return cast.ToString(sn.MustGet(k))
+}
+
+// String gets the value with the key k from the snapshot and casts it to a string.
+// It returns the zero value if the key is not set.
+func (sn *Snapshot) String(k string) string {
+	return cast.ToString(sn.Get(k))
+}
+
+// MustBool gets the value with the key k from the snapshot and casts it to a bool, it panics if the key is not set
+func (sn *Snapshot) MustBool(k string) bool {
+	return cast.ToBool(sn.MustGet(k))
+}
+
+// Bool gets the value with the key k from the snapshot and casts it to a bool.
+// It returns the zero value if the key is not set.
+func (sn *Snapshot) Bool(k string) bool {
+	return cast.ToBool(sn.Get(k))
+}
+
+// MustDuration gets the value with the key k from the snapshot and casts it to a time.Duration, it panics if the key is not set
+func (sn *Snapshot) MustDuration(k string) time.Duration {
+	return cast.ToDuration(sn.MustGet(k))
+}
+
+// Duration gets the value with the key k from the snapshot and casts it to a time.Duration.
+// It returns the zero value if the key is not set.
+func (sn *Snapshot) Duration(k string) time.Duration {
+	return cast.ToDuration(sn.Get(k))
+}
+
+// MustTime gets the value with the key k from the snapshot and casts it to a time.Time, it panics if the key is not set
+func (sn *Snapshot) MustTime(k string) time.Time {
+	return cast.ToTime(sn.MustGet(k))
+}
+
+// Time gets the value with the key k from the snapshot and casts it to a time.Time.
+// It returns the zero value if the key is not set.
+func (sn *Snapshot) Time(k string) time.Time {
+	return cast.ToTime(sn.Get(k))
+}
+
+// MustStringSlice gets the value with the key k from the snapshot and casts it to a []string, it panics if the key is not set
+func (sn *Snapshot) MustStringSlice(k string) []string {
+	return cast.ToStringSlice(sn.MustGet(k))
+}
+
+// StringSlice gets the value with the key k from the snapshot and casts it to a []string.
+// It returns the zero value if the key is not set.
+func (sn *Snapshot) StringSlice(k string) []string {
+	return cast.ToStringSlice(sn.Get(k))
+}
+
+// MustIntSlice gets the value with the key k from the snapshot and casts it to a []int, it panics if the key is not set
+func (sn *Snapshot) MustIntSlice(k string) []int {
+	return cast.ToIntSlice(sn.MustGet(k))
+}
+
+// IntSlice gets the value with the key k from the snapshot and casts it to a []int.
+// It returns the zero value if the key is not set.
+func (sn *Snapshot) IntSlice(k string) []int {
+	return cast.ToIntSlice(sn.Get(k))
+}
+
+// MustStringMap gets the value with the key k from the snapshot and casts it to a map[string]interface{}, it panics if the key is not set
+func (sn *Snapshot) MustStringMap(k string) map[string]interface{} {
+	return cast.ToStringMap(sn.MustGet(k))
+}
+
+// StringMap gets the value with the key k from the snapshot and casts it to a map[string]interface{}.
+// It returns the zero value if the key is not set.
+func (sn *Snapshot) StringMap(k string) map[string]interface{} {
+	return cast.ToStringMap(sn.Get(k))
+}
+
+// MustStringMapString gets the value with the key k from the snapshot and casts it to a map[string]string, it panics if the key is not set
+func (sn *Snapshot) MustStringMapString(k string) map[string]string {
+	return cast.ToStringMapString(sn.MustGet(k))
+}
+
+// StringMapString gets the value with the key k from the snapshot and casts it to a map[string]string.
+// It returns the zero value if the key is not set.
+func (sn *Snapshot) StringMapString(k string) map[string]string {
+	return cast.ToStringMapString(sn.Get(k))
+}
@@ -0,0 +1,46 @@
+package konfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestView(t *testing.T) {
+	var c = New(DefaultConfig())
+	var l = &valuesLoader{values: Values{
+		"db.host":    "localhost",
+		"db.port":    "5432",
+		"db.timeout": "1s",
+		"db.ssl":     "true",
+		"db.hosts":   []string{"a", "b"},
+	}}
+	var cl = c.RegisterLoader(l)
+	require.Nil(t, c.Load())
+
+	var v = c.View()
+
+	l.values = Values{"db.host": "remote"}
+	require.Nil(t, c.loaderLoadRetry(context.Background(), cl.loaderWatcher, 0))
+	require.Equal(t, "remote", c.String("db.host"))
+
+	// the view is not affected by the reload
+	require.Equal(t, "localhost", v.MustString("db.host"))
+	require.Equal(t, 5432, v.Int("db.port"))
+	require.Equal(t, time.Second, v.MustDuration("db.timeout"))
+	require.True(t, v.Bool("db.ssl"))
+	require.Equal(t, []string{"a", "b"}, v.StringSlice("db.hosts"))
+	require.True(t, v.Exists("db.port"))
+	require.False(t, v.Exists("foo"))
+	require.Nil(t, v.Get("foo"))
+	require.Equal(t, "", v.String("foo"))
+	require.Panics(t, func() { v.MustInt("foo") })
+
+	var port, err = GetAs[int](v, "db.port")
+	require.Nil(t, err)
+	require.Equal(t, 5432, port)
+
+	require.Equal(t, "remote", c.View().String("db.host"))
+}
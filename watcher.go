@@ -1,5 +1,7 @@
 package konfig
 
+import "context"
+
 // Watcher is the interface implementing a config watcher.
 // Config watcher trigger loaders. A file watcher or a simple
 // Timer can be valid watchers.
@@ -57,6 +59,16 @@ func Watch() error {
 
 // Watch starts the watchers on loaders
 func (c *S) Watch() error {
+	return c.WatchContext(context.Background())
+}
+
+// WatchContext starts the watchers on loaders, they are stopped when ctx is done
+func WatchContext(ctx context.Context) error {
+	return instance().WatchContext(ctx)
+}
+
+// WatchContext starts the watchers on loaders, they are stopped when ctx is done or when the store is closed
+func (c *S) WatchContext(ctx context.Context) error {
 
 	// if metrics are enabled, we register them in prometheus
 	if c.cfg.Metrics {
@@ -65,11 +77,23 @@ func (c *S) Watch() error {
 		}
 	}
 
+	// the context is cancelled when ctx is done or when the store is closed,
+	// it lives as long as the watchers so we cancel it only if a watcher fails to start
+	var wCtx, cancel = c.withQuit(ctx)
+
 	for _, wl := range c.WatcherLoaders {
+		// the store is closed, there is nothing to watch
+		if !c.addWatcher() {
+			cancel()
+			return nil
+		}
 		if err := wl.Start(); err != nil {
+			c.wg.Done()
+			cancel()
 			return err
 		}
-		go c.watchLoader(wl, 1)
+		c.setWatching(wl, true)
+		go c.watchLoader(wCtx, wl, 1)
 	}
 	return nil
 }